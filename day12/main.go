@@ -194,7 +194,7 @@ func (r Region) flood(loc shared.Coord) Region {
 
 func (r Region) dumbSideCount() int {
 	runs := r.dumbRunCount()
-	r.Grid.Rotate("R")
+	r.Grid.Rotate(1)
 	runs += r.dumbRunCount()
 
 	return runs
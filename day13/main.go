@@ -1,12 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"math"
 	"os"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -34,8 +34,8 @@ func PartOne(clawMachines []ClawMachine) int {
 
 	for i, clawMachine := range clawMachines {
 		for aPresses := 0; aPresses <= 100; aPresses++ {
-			xRem := float64(clawMachine.prize.X-(clawMachine.buttonA.X*aPresses)) / float64(clawMachine.buttonB.X)
-			yRem := float64(clawMachine.prize.Y-(clawMachine.buttonA.Y*aPresses)) / float64(clawMachine.buttonB.Y)
+			xRem := float64(clawMachine.Prize.X-(clawMachine.ButtonA.X*aPresses)) / float64(clawMachine.ButtonB.X)
+			yRem := float64(clawMachine.Prize.Y-(clawMachine.ButtonA.Y*aPresses)) / float64(clawMachine.ButtonB.Y)
 
 			if xRem != yRem || xRem != math.Trunc(xRem) {
 				continue
@@ -60,25 +60,25 @@ func PartTwo(clawMachines []ClawMachine) int {
 	totalCost := 0
 
 	for i, clawMachine := range clawMachines {
-		clawMachine.prize.X += 10000000000000
-		clawMachine.prize.Y += 10000000000000
+		clawMachine.Prize.X += 10000000000000
+		clawMachine.Prize.Y += 10000000000000
 
 		// C, D → Prize
-		C := float64(clawMachine.prize.X)
-		D := float64(clawMachine.prize.Y)
+		C := float64(clawMachine.Prize.X)
+		D := float64(clawMachine.Prize.Y)
 
 		// y = Kx + J → Button A (except we assume we're starting at 0,0)
 		// y = Px + Q → Button B
-		K := float64(clawMachine.buttonA.Y) / float64(clawMachine.buttonA.X)
+		K := float64(clawMachine.ButtonA.Y) / float64(clawMachine.ButtonA.X)
 
-		P := float64(clawMachine.buttonB.Y) / float64(clawMachine.buttonB.X)
+		P := float64(clawMachine.ButtonB.Y) / float64(clawMachine.ButtonB.X)
 		Q := D - (P * C)
 
 		// intersection
 		x := Q / (K - P)
 
-		aPresses := int(math.Round(x / float64(clawMachine.buttonA.X)))
-		bPresses := int(math.Round((C - x) / float64(clawMachine.buttonB.X)))
+		aPresses := int(math.Round(x / float64(clawMachine.ButtonA.X)))
+		bPresses := int(math.Round((C - x) / float64(clawMachine.ButtonB.X)))
 
 		cost, win := clawMachine.play(aPresses, bPresses)
 
@@ -99,51 +99,41 @@ func readInput(filePath string) []ClawMachine {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
 	clawMachines := []ClawMachine{}
-	var buttonAx, buttonAy, buttonBx, buttonBy, prizeX, prizeY int
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		fmt.Sscanf(line, "Button A: X+%d, Y+%d", &buttonAx, &buttonAy)
-		fmt.Sscanf(line, "Button B: X+%d, Y+%d", &buttonBx, &buttonBy)
-		i, err := fmt.Sscanf(line, "Prize: X=%d, Y=%d", &prizeX, &prizeY)
 
-		if err == nil && i == 2 {
-			clawMachines = append(clawMachines, ClawMachine{buttonA: shared.Coord{X: buttonAx, Y: buttonAy}, buttonB: shared.Coord{X: buttonBx, Y: buttonBy}, prize: shared.Coord{X: prizeX, Y: prizeY}})
-		}
+	if err := input.Decode(file, &clawMachines); err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil
 	}
 
 	return clawMachines
 }
 
 type ClawMachine struct {
-	buttonA shared.Coord
-	buttonB shared.Coord
-	prize   shared.Coord
+	ButtonA shared.Coord `input:"Button A: X+{X}, Y+{Y}"`
+	ButtonB shared.Coord `input:"Button B: X+{X}, Y+{Y}"`
+	Prize   shared.Coord `input:"Prize: X={X}, Y={Y}"`
 }
 
 func (c ClawMachine) play(aPresses int, bPresses int) (int, bool) {
 	position := shared.Coord{X: 0, Y: 0}
 	cost := 0
 
-	position.X += aPresses * c.buttonA.X
-	position.Y += aPresses * c.buttonA.Y
+	position.X += aPresses * c.ButtonA.X
+	position.Y += aPresses * c.ButtonA.Y
 	cost += aPresses * 3
 
-	position.X += bPresses * c.buttonB.X
-	position.Y += bPresses * c.buttonB.Y
+	position.X += bPresses * c.ButtonB.X
+	position.Y += bPresses * c.ButtonB.Y
 	cost += bPresses * 1
 
-	return cost, position == c.prize
+	return cost, position == c.Prize
 }
 
 func (c ClawMachine) maxIters() int {
-	maxDimension := math.Max(float64(c.prize.X), float64(c.prize.Y))
-	minIncrementX := math.Min(float64(c.buttonA.X), float64(c.buttonB.X))
-	minIncrementY := math.Min(float64(c.buttonB.Y), float64(c.buttonB.Y))
+	maxDimension := math.Max(float64(c.Prize.X), float64(c.Prize.Y))
+	minIncrementX := math.Min(float64(c.ButtonA.X), float64(c.ButtonB.X))
+	minIncrementY := math.Min(float64(c.ButtonB.Y), float64(c.ButtonB.Y))
 	minIncrement := math.Min(minIncrementX, minIncrementY)
 
 	return int(math.Trunc(maxDimension/minIncrement) + 1)
@@ -1,13 +1,13 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
 	"fmt"
 	"os"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -40,11 +40,11 @@ func PartOne(input []Robot, gridSize shared.Coord) int {
 	for i := range robots {
 		robots[i].move(100, gridSize)
 
-		if robots[i].pos.X == center.X || robots[i].pos.Y == center.Y {
+		if robots[i].Pos.X == center.X || robots[i].Pos.Y == center.Y {
 			continue
 		}
 
-		quads[robots[i].pos.X < center.X][robots[i].pos.Y < center.Y]++
+		quads[robots[i].Pos.X < center.X][robots[i].Pos.Y < center.Y]++
 	}
 
 	safetyScore := quads[false][false] * quads[false][true] * quads[true][false] * quads[true][true]
@@ -94,19 +94,16 @@ func readInput(filePath string) []Robot {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	robots := []Robot{}
-
-	for scanner.Scan() {
-		line := scanner.Text()
+	var parsed struct {
+		Robots []Robot `input:"p={Pos.X},{Pos.Y} v={Vel.X},{Vel.Y}"`
+	}
 
-		pX, pY, vX, vY := 0, 0, 0, 0
-		fmt.Sscanf(line, "p=%d,%d v=%d,%d", &pX, &pY, &vX, &vY)
-		robots = append(robots, Robot{pos: shared.Coord{X: pX, Y: pY}, vel: shared.Coord{X: vX, Y: vY}})
+	if err := input.Decode(file, &parsed); err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil
 	}
 
-	return robots
+	return parsed.Robots
 }
 
 func copyRobots(robots []Robot) []Robot {
@@ -116,23 +113,23 @@ func copyRobots(robots []Robot) []Robot {
 }
 
 type Robot struct {
-	pos shared.Coord
-	vel shared.Coord
+	Pos shared.Coord
+	Vel shared.Coord
 }
 
 func (r *Robot) move(seconds int, gridSize shared.Coord) {
-	(*r).pos.X += (*r).vel.X * seconds
-	(*r).pos.Y += (*r).vel.Y * seconds
+	(*r).Pos.X += (*r).Vel.X * seconds
+	(*r).Pos.Y += (*r).Vel.Y * seconds
 
-	(*r).pos.X = (*r).pos.X % gridSize.X
-	(*r).pos.Y = (*r).pos.Y % gridSize.Y
+	(*r).Pos.X = (*r).Pos.X % gridSize.X
+	(*r).Pos.Y = (*r).Pos.Y % gridSize.Y
 
-	if (*r).pos.X < 0 {
-		(*r).pos.X += gridSize.X
+	if (*r).Pos.X < 0 {
+		(*r).Pos.X += gridSize.X
 	}
 
-	if (*r).pos.Y < 0 {
-		(*r).pos.Y += gridSize.Y
+	if (*r).Pos.Y < 0 {
+		(*r).Pos.Y += gridSize.Y
 	}
 }
 
@@ -145,7 +142,7 @@ func plot(r []Robot, gridSize shared.Coord) (int, [][]bool) {
 	}
 
 	for _, robot := range r {
-		grid[robot.pos.Y][robot.pos.X] = true
+		grid[robot.Pos.Y][robot.Pos.X] = true
 	}
 
 	for y := 0; y < gridSize.Y; y++ {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/gridtest"
 )
 
 type testCase struct {
@@ -30,3 +31,47 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalState(t *testing.T) {
+	cases := []struct {
+		fileName string
+		wide     bool
+		golden   string
+	}{
+		{"input_small.txt", false, "golden_small.txt"},
+		{"input_medium.txt", false, "golden_medium.txt"},
+		{"input_medium.txt", true, "golden_medium_wide.txt"},
+	}
+
+	for _, c := range cases {
+		grid, moves := readInput(c.fileName)
+		warehouse := simulate(grid, moves, c.wide, nil)
+		gridtest.Golden(t, c.golden, warehouse.Grid)
+	}
+}
+
+func TestSteps(t *testing.T) {
+	cases := []struct {
+		fileName string
+		wide     bool
+		golden   string
+	}{
+		{"input_small.txt", false, "golden_steps_small.txt"},
+		{"input_wide.txt", true, "golden_steps_wide.txt"},
+	}
+
+	for _, c := range cases {
+		grid, moves := readInput(c.fileName)
+		steps := []gridtest.Step{}
+
+		simulate(grid, moves, c.wide, func(step int, board shared.Grid) {
+			label := "Initial state"
+			if step > 0 {
+				label = "Move " + moves[step-1]
+			}
+			steps = append(steps, gridtest.Step{Label: label, Grid: board})
+		})
+
+		gridtest.GoldenSteps(t, c.golden, steps)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"math"
 	"os"
@@ -10,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -33,27 +33,55 @@ func main() {
 }
 
 func PartOne(grid shared.Grid, moves []string) int {
-	warehouse := Warehouse{Grid: grid, direction: N}
+	warehouse := simulate(grid, moves, false, nil)
+	warehouse.draw()
 
-	for _, move := range moves {
-		warehouse.moveRobot(move)
-	}
+	return warehouse.gpsValue()
+}
 
+func PartTwo(grid shared.Grid, moves []string) int {
+	warehouse := simulate(grid, moves, true, nil)
 	warehouse.draw()
 
 	return warehouse.gpsValue()
 }
 
-func PartTwo(grid shared.Grid, moves []string) int {
-	warehouse := Warehouse{Grid: grid}
-	warehouse.widen()
+// simulate runs every move and returns the warehouse facing north. If after
+// isn't nil it's called with a copy of the initial board, as step 0, and
+// again after each move, with the board facing north.
+func simulate(grid shared.Grid, moves []string, wide bool, after func(step int, board shared.Grid)) Warehouse {
+	warehouse := Warehouse{Grid: grid, direction: N}
 
-	for _, move := range moves {
-		warehouse.wideMoveRobot(move)
+	if wide {
+		warehouse.widen()
 	}
-	warehouse.draw()
 
-	return warehouse.gpsValue()
+	report := func(step int) {
+		if after == nil {
+			return
+		}
+
+		// turning a copy leaves the warehouse as the moves expect it
+		board := warehouse
+		board.turnToFace(N)
+		after(step, board.View(shared.Identity).ToGrid())
+	}
+
+	report(0)
+
+	for i, move := range moves {
+		if wide {
+			warehouse.wideMoveRobot(move)
+		} else {
+			warehouse.moveRobot(move)
+		}
+
+		report(i + 1)
+	}
+
+	warehouse.turnToFace(N)
+
+	return warehouse
 }
 
 func readInput(filePath string) (shared.Grid, []string) {
@@ -64,28 +92,23 @@ func readInput(filePath string) (shared.Grid, []string) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	grid := shared.Grid{}
-	moves := []string{}
-
-	readMoves := false
+	blocks, err := input.Blocks(file)
+	if err != nil || len(blocks) != 2 {
+		fmt.Printf("Error reading %s", filePath)
+		return nil, nil
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	grid, err := input.Grid(blocks[0])
+	if err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil, nil
+	}
 
-		if line == "" {
-			readMoves = true
-			continue
-		}
+	moves := []string{}
 
-		if readMoves {
-			lineMoves := strings.Split(line, "")
-			moves = append(moves, lineMoves...)
-		} else {
-			lineGrid := strings.Split(line, "")
-			grid = append(grid, lineGrid)
-		}
+	for _, line := range blocks[1] {
+		lineMoves := strings.Split(line, "")
+		moves = append(moves, lineMoves...)
 	}
 
 	return grid, moves
@@ -105,9 +128,6 @@ const E = 1
 const S = 2
 const W = 3
 
-const CCW = 0
-const CW = 1
-
 const UP = "^"
 const RIGHT = ">"
 const DOWN = "v"
@@ -124,26 +144,9 @@ func (w *Warehouse) turnToFace(dir int) {
 		return
 	}
 
-	var turns []int
-
-	if (dir == N && w.direction == E) ||
-		(dir == E && w.direction == S) ||
-		(dir == S && w.direction == W) ||
-		(dir == W && w.direction == N) {
-		turns = []int{CCW}
-	} else if (dir == N && w.direction == W) ||
-		(dir == W && w.direction == S) ||
-		(dir == S && w.direction == E) ||
-		(dir == E && w.direction == N) {
-		turns = []int{CW}
-	} else {
-		turns = []int{CCW, CCW}
-	}
-
-	for _, turn := range turns {
-		(*w).rotate(turn)
-	}
-
+	// directions are numbered clockwise, so the difference is the number of
+	// quarter turns needed
+	(*w).Grid.Rotate(dir - w.direction)
 	(*w).direction = dir
 }
 
@@ -175,26 +178,6 @@ func (w *Warehouse) widen() {
 	(*w).wide = true
 }
 
-func (w *Warehouse) rotate(dir int) {
-	// rotate the grid
-	width, height := (*w).Width(), (*w).Height()
-	result := shared.MakeGrid(width, height)
-
-	for y := 0; y < width; y++ {
-		for x := 0; x < height; x++ {
-			if dir == CCW {
-				result[height-1-x][y] = (*w).Grid[y][x]
-			}
-
-			if dir == CW {
-				result[x][height-1-y] = (*w).Grid[y][x]
-			}
-		}
-	}
-
-	(*w).Grid = result
-}
-
 func (w Warehouse) draw() {
 	w.turnToFace(N)
 
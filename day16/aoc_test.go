@@ -1,6 +1,12 @@
 package main
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
 
 type testCase struct {
 	fileName      string
@@ -25,3 +31,77 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestScoring(t *testing.T) {
+	maze := readInput("input_small.txt")
+
+	cases := []struct {
+		scoring       Scoring
+		expectedCost  int
+		expectedTiles int
+	}{
+		{DefaultScoring, 7036, 45},
+		{Scoring{Step: 1, Turn: 0, Facing: "E"}, 28, 37},
+		{Scoring{Step: 2, Turn: 0, Facing: "N"}, 56, 37},
+		{Scoring{Step: 1, Turn: 1000, Facing: "N"}, 6036, 45},
+	}
+
+	for _, c := range cases {
+		cost, tiles, err := SolveWith(maze, c.scoring)
+		if err != nil || cost != c.expectedCost || tiles != c.expectedTiles {
+			t.Errorf("%+v: expected cost: %d - tiles: %d, got cost: %d - tiles: %d (%v)", c.scoring, c.expectedCost, c.expectedTiles, cost, tiles, err)
+		}
+	}
+
+	// turning around costs one turn, like any other change of direction
+	corridor := Maze{shared.Grid{strings.Split("#S..E#", "")}}
+	if cost, _, _ := SolveWith(corridor, Scoring{Step: 1, Turn: 1000, Facing: "W"}); cost != 1003 {
+		t.Errorf("expected the U-turn to cost 1003, got %d", cost)
+	}
+	if routes, _ := Routes(corridor, Scoring{Step: 1, Turn: 1000, Facing: "W"}, 1); len(routes) != 1 || routes[0].Cost != 1003 || routes[0].Turns != 1 {
+		t.Errorf("expected one route costing 1003 with 1 turn, got %+v", routes)
+	}
+
+	for _, bad := range []Scoring{{Step: 0, Turn: 1, Facing: "E"}, {Step: 1, Turn: -1, Facing: "E"}, {Step: 1, Turn: 1, Facing: "up"}} {
+		if _, _, err := SolveWith(maze, bad); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	maze := readInput("input_small.txt")
+	expectedCosts := []int{7036, 7036, 7036, 9040, 10028}
+	expectedTurns := []int{7, 7, 7, 9, 10}
+
+	routes, err := Routes(maze, DefaultScoring, len(expectedCosts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != len(expectedCosts) {
+		t.Fatalf("expected %d routes, got %d", len(expectedCosts), len(routes))
+	}
+
+	for i, route := range routes {
+		if route.Cost != expectedCosts[i] || route.Turns != expectedTurns[i] {
+			t.Errorf("route %d: expected cost %d with %d turns, got cost %d with %d turns", i, expectedCosts[i], expectedTurns[i], route.Cost, route.Turns)
+		}
+
+		if route.Tiles[0] != maze.LocationOf(START) || route.Tiles[len(route.Tiles)-1] != maze.LocationOf(END) {
+			t.Errorf("route %d: runs from %v to %v", i, route.Tiles[0], route.Tiles[len(route.Tiles)-1])
+		}
+
+		for j := range i {
+			if slices.Equal(routes[j].Tiles, route.Tiles) {
+				t.Errorf("routes %d and %d are the same", j, i)
+			}
+		}
+	}
+
+	// a dead end has only one way through
+	corridor := Maze{shared.Grid{strings.Split("#S..E#", "")}}
+	routes, _ = Routes(corridor, DefaultScoring, 3)
+	if len(routes) != 1 || routes[0].Cost != 3 || routes[0].Turns != 0 {
+		t.Errorf("corridor: expected one route costing 3, got %+v", routes)
+	}
+}
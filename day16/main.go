@@ -2,28 +2,51 @@ package main
 
 import (
 	"bufio"
+	"container/heap"
+	"flag"
 	"fmt"
-	"math"
 	"os"
-	"slices"
 	"strings"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
 )
 
 func main() {
+	step := flag.Int("step", DefaultScoring.Step, "cost of moving forward one tile")
+	turn := flag.Int("turn", DefaultScoring.Turn, "cost of changing direction")
+	facing := flag.String("facing", DefaultScoring.Facing, "direction the reindeer starts out facing: N, E, S or W")
+	routes := flag.Int("routes", 0, "list the cheapest this many distinct routes and exit")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
 
 	maze := readInput(fileName)
+	scoring := Scoring{Step: *step, Turn: *turn, Facing: *facing}
+
+	if *routes > 0 {
+		found, err := Routes(maze, scoring, *routes)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for i, route := range found {
+			fmt.Printf("Route %d: cost %d, %d steps, %d turns\n", i+1, route.Cost, len(route.Tiles)-1, route.Turns)
+		}
+		return
+	}
 
 	// part 1 & 2
-	bestCost, optimalTiles := Solve(maze)
+	bestCost, optimalTiles, err := SolveWith(maze, scoring)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Printf("Lowest cost: %d\n", bestCost)
 	fmt.Printf("Optimal path count: %d\n", optimalTiles)
 }
@@ -46,87 +69,160 @@ func readInput(filePath string) Maze {
 	return grid
 }
 
+// Scoring prices the reindeer's moves: Step for each tile it moves forward and
+// Turn for each change of direction, starting out facing Facing. Turning
+// around costs one Turn, the same as turning left or right.
+type Scoring struct {
+	Step   int
+	Turn   int
+	Facing string
+}
+
+// DefaultScoring is the Reindeer Olympics' scoring
+var DefaultScoring = Scoring{Step: 1, Turn: 1000, Facing: "E"}
+
+func (s Scoring) Validate() error {
+	if s.Step <= 0 || s.Turn < 0 {
+		return fmt.Errorf("steps must cost more than 0 and turns at least 0, got %d and %d", s.Step, s.Turn)
+	}
+
+	if !headings[s.Facing] {
+		return fmt.Errorf("can't start facing %q, expected N, E, S or W", s.Facing)
+	}
+
+	return nil
+}
+
+// headings are the directions the reindeer can start out facing
+var headings = map[string]bool{"N": true, "E": true, "S": true, "W": true}
+
+// turns is how many turns it takes to face to from from
+func turns(from, to string) int {
+	if from == to {
+		return 0
+	}
+
+	return 1
+}
+
+// Solve scores the maze the Reindeer Olympics' way
 func Solve(m Maze) (int, int) {
-	start := m.LocationOf(START)
-	end := m.LocationOf(END)
+	cost, tiles, _ := SolveWith(m, DefaultScoring)
+	return cost, tiles
+}
+
+// SolveWith finds the lowest score and how many tiles sit on some path with
+// that score. The cost is -1 when the end can't be reached.
+func SolveWith(m Maze, s Scoring) (int, int, error) {
+	if err := s.Validate(); err != nil {
+		return 0, 0, err
+	}
 
-	queue := DumbQueue{}
-	queue.push(State{
-		dir:  "E",
-		cost: 0,
-		path: []shared.Coord{start},
-	})
+	end := m.LocationOf(END)
+	search := m.search(s, Pose{loc: m.LocationOf(START), dir: s.Facing}, end, nil)
 
-	bestCost := math.MaxInt
-	visitedCost := map[shared.Coord]int{}
-	bestPaths := [][]shared.Coord{}
+	if search.best < 0 {
+		return -1, 0, nil
+	}
 
-	for len(queue) > 0 {
-		current := queue.pop()
+	// walk back from every way of reaching the end at the best cost
+	pending := search.ends()
+	seen := map[Pose]bool{}
+	tiles := map[shared.Coord]bool{}
 
-		// fill a cost grid (not checking direction)
-		localBestCost, ok := visitedCost[current.Loc()]
+	for len(pending) > 0 {
+		pose := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
 
-		if !ok || current.cost < localBestCost {
-			visitedCost[current.Loc()] = current.cost
-			localBestCost = current.cost
+		if seen[pose] {
+			continue
 		}
+		seen[pose] = true
+		tiles[pose.loc] = true
+
+		pending = append(pending, search.previous[pose]...)
+	}
+
+	bestTiles := []shared.Coord{}
+	for tile := range tiles {
+		bestTiles = append(bestTiles, tile)
+	}
 
-		// if another path has crossed here with a much lower cost, skip
-		if current.cost > bestCost || current.cost > (localBestCost+1000) {
+	m.Draw(map[string]string{"S": "🟢", "E": "🔴"}, map[string][]shared.Coord{"⏺️ ": bestTiles})
+
+	return search.best, len(bestTiles), nil
+}
+
+// search is the result of a Dijkstra run over (tile, facing) pairs. previous
+// holds every cheapest way into each pose, so all best paths can be rebuilt.
+type search struct {
+	end      shared.Coord
+	best     int
+	cost     map[Pose]int
+	previous map[Pose][]Pose
+}
+
+// search runs Dijkstra from start until every pose cheaper than the end has
+// been expanded. blocked, when given, rules out individual moves.
+func (m Maze) search(s Scoring, start Pose, end shared.Coord, blocked func(from, to shared.Coord) bool) search {
+	queue := NewPriorityQueue()
+	queue.PushState(&State{Pose: start, cost: 0})
+
+	result := search{
+		end:      end,
+		best:     -1,
+		cost:     map[Pose]int{start: 0},
+		previous: map[Pose][]Pose{},
+	}
+
+	for queue.Len() > 0 {
+		current := queue.PopState()
+
+		// a cheaper way here was already expanded
+		if current.cost > result.cost[current.Pose] || (result.best >= 0 && current.cost > result.best) {
 			continue
 		}
 
-		// if we're at the end, save and continue
-		if current.Loc() == end {
-			if current.cost < bestCost {
-				bestPaths = [][]shared.Coord{current.path}
-				bestCost = current.cost
-			} else if current.cost == bestCost {
-				bestPaths = append(bestPaths, current.path)
-			}
+		if current.loc == end {
+			result.best = current.cost
 			continue
 		}
 
-		for newDir, neighbor := range m.Neighbors(current.Loc()) {
-			if slices.Contains(current.path, neighbor) {
+		for newDir, neighbor := range m.Neighbors(current.loc) {
+			if blocked != nil && blocked(current.loc, neighbor) {
 				continue
 			}
 
-			newCost := current.cost + 1
-
-			if newDir != current.dir {
-				newCost += 1000
-			}
+			newCost := current.cost + s.Step + s.Turn*turns(current.dir, newDir)
 
-			queue.push(State{
-				dir:  newDir,
-				cost: newCost,
-				path: CopyAppend(current.path, neighbor),
-			})
-		}
-	}
+			next := Pose{loc: neighbor, dir: newDir}
+			known, ok := result.cost[next]
 
-	bestTiles := []shared.Coord{}
-	for _, path := range bestPaths {
-		for _, loc := range path {
-			if !slices.Contains(bestTiles, loc) {
-				bestTiles = append(bestTiles, loc)
+			switch {
+			case !ok || newCost < known:
+				result.cost[next] = newCost
+				result.previous[next] = []Pose{current.Pose}
+				queue.PushState(&State{Pose: next, cost: newCost})
+			case newCost == known:
+				result.previous[next] = append(result.previous[next], current.Pose)
 			}
 		}
 	}
 
-	m.Draw(map[string]string{"S": "🟢", "E": "🔴"}, map[string][]shared.Coord{"⏺️ ": bestTiles})
-
-	return bestCost, len(bestTiles)
+	return result
 }
 
-func CopyAppend(a []shared.Coord, b shared.Coord) []shared.Coord {
-	newSlice := make([]shared.Coord, len(a)+1)
-	copy(newSlice, a)
-	newSlice[len(a)] = b
+// ends lists the poses that reach the end at the best cost
+func (r search) ends() []Pose {
+	poses := []Pose{}
+	for _, dir := range []string{"N", "E", "S", "W"} {
+		pose := Pose{loc: r.end, dir: dir}
+		if known, ok := r.cost[pose]; ok && known == r.best {
+			poses = append(poses, pose)
+		}
+	}
 
-	return newSlice
+	return poses
 }
 
 const FILL = "="
@@ -143,22 +239,56 @@ func (m Maze) Neighbors(loc shared.Coord) map[string]shared.Coord {
 	return m.Grid.Neighbors(loc, []string{WALL, FILL})
 }
 
+// Pose is where the reindeer is and which way it's facing
+type Pose struct {
+	loc shared.Coord
+	dir string
+}
+
 type State struct {
-	dir  string
+	Pose
 	cost int
-	path []shared.Coord
 }
 
-func (s State) Loc() shared.Coord {
-	return s.path[len(s.path)-1]
+type Queue []*State
+
+func (q Queue) Len() int { return len(q) }
+
+func (q Queue) Less(i, j int) bool {
+	return q[i].cost < q[j].cost
 }
 
-type DumbQueue []State
+func (q Queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
 
-func (q *DumbQueue) push(item State) { *q = append(*q, item) }
+func (q *Queue) Push(x interface{}) {
+	item := x.(*State)
+	*q = append(*q, item)
+}
 
-func (q *DumbQueue) pop() State {
-	item := (*q)[len(*q)-1]
-	*q = (*q)[0 : len(*q)-1]
+func (q *Queue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[0 : n-1]
 	return item
 }
+
+// NewPriorityQueue creates and initializes a new priority queue
+func NewPriorityQueue() *Queue {
+	q := &Queue{}
+	heap.Init(q)
+	return q
+}
+
+// PushState adds a new State to the priority queue
+func (q *Queue) PushState(state *State) {
+	heap.Push(q, state)
+}
+
+// PopState removes and returns the cheapest State
+func (q *Queue) PopState() *State {
+	return heap.Pop(q).(*State)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+// Route is one way through the maze, from the start tile to the end tile
+type Route struct {
+	Tiles []shared.Coord
+	Cost  int
+	Turns int
+}
+
+// score prices a route that starts out facing s.Facing
+func (s Scoring) score(tiles []shared.Coord) Route {
+	route := Route{Tiles: tiles}
+	facing := s.Facing
+
+	for i := 1; i < len(tiles); i++ {
+		dir := direction(tiles[i-1], tiles[i])
+		route.Turns += turns(facing, dir)
+		route.Cost += s.Step
+		facing = dir
+	}
+
+	route.Cost += route.Turns * s.Turn
+
+	return route
+}
+
+// direction is the way to step from a to reach the neighboring tile b
+func direction(a, b shared.Coord) string {
+	for dir, neighbor := range a.Neighbors() {
+		if neighbor == b {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+// path rebuilds one cheapest route to the end, following the first way into
+// each pose
+func (r search) path() []shared.Coord {
+	ends := r.ends()
+	if len(ends) == 0 {
+		return nil
+	}
+
+	tiles := []shared.Coord{}
+	for pose := ends[0]; ; pose = r.previous[pose][0] {
+		tiles = append(tiles, pose.loc)
+		if len(r.previous[pose]) == 0 {
+			break
+		}
+	}
+
+	slices.Reverse(tiles)
+
+	return tiles
+}
+
+// Routes finds the k cheapest routes through the maze that differ in at least
+// one tile, cheapest first, with Yen's algorithm. No route visits a tile
+// twice. Fewer than k come back when the maze runs out of routes.
+func Routes(m Maze, s Scoring, k int) ([]Route, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	start, end := m.LocationOf(START), m.LocationOf(END)
+
+	first := m.search(s, Pose{loc: start, dir: s.Facing}, end, nil).path()
+	if first == nil || k <= 0 {
+		return []Route{}, nil
+	}
+
+	routes := []Route{s.score(first)}
+	candidates := []Route{}
+
+	known := func(tiles []shared.Coord) bool {
+		same := func(r Route) bool { return slices.Equal(r.Tiles, tiles) }
+		return slices.ContainsFunc(routes, same) || slices.ContainsFunc(candidates, same)
+	}
+
+	for len(routes) < k {
+		last := routes[len(routes)-1].Tiles
+
+		// branch off the last route at each of its tiles in turn
+		for i := 0; i < len(last)-1; i++ {
+			root := last[:i+1]
+			spur := last[i]
+
+			// don't take a step another route with the same root took from here,
+			// or step back onto the root
+			cut := map[[2]shared.Coord]bool{}
+			for _, route := range routes {
+				if len(route.Tiles) > i+1 && slices.Equal(route.Tiles[:i+1], root) {
+					cut[[2]shared.Coord{spur, route.Tiles[i+1]}] = true
+				}
+			}
+
+			blocked := func(from, to shared.Coord) bool {
+				return cut[[2]shared.Coord{from, to}] || slices.Contains(root[:i], to)
+			}
+
+			facing := s.Facing
+			if i > 0 {
+				facing = direction(last[i-1], spur)
+			}
+
+			branch := m.search(s, Pose{loc: spur, dir: facing}, end, blocked).path()
+			if branch == nil {
+				continue
+			}
+
+			tiles := append(slices.Clone(root[:i]), branch...)
+			if !known(tiles) {
+				candidates = append(candidates, s.score(tiles))
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		cheapest := 0
+		for i, candidate := range candidates {
+			if candidate.Cost < candidates[cheapest].Cost {
+				cheapest = i
+			}
+		}
+
+		routes = append(routes, candidates[cheapest])
+		candidates = slices.Delete(candidates, cheapest, cheapest+1)
+	}
+
+	return routes, nil
+}
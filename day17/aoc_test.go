@@ -1,6 +1,25 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/too-gee/advent-of-code-2024/shared/input"
+)
+
+// part2 fits Part2 into the same table as Part1
+func part2(registers []int64, program []int) ([]int64, string) {
+	registers, output, err := Part2(registers, program)
+	if err != nil {
+		return []int64{-1, -1, -1}, err.Error()
+	}
+
+	return registers, output
+}
 
 type testCase struct {
 	fileName          string
@@ -18,8 +37,8 @@ func TestAll(t *testing.T) {
 		{"input_small_bxc.txt", Part1, []int64{0, 44354, 43690}, ""},
 		{"input_small.txt", Part1, []int64{0, 0, 0}, "4,6,3,5,6,3,5,2,1,0"},
 		{"input.txt", Part1, []int64{0, 7, 0}, "4,1,5,3,1,5,3,5,7"},
-		{"input_small_quine.txt", Part2, []int64{0, 0, 0}, "117440"},
-		{"input.txt", Part2, []int64{0, 0, 0}, "164542125272765"},
+		{"input_small_quine.txt", part2, []int64{0, 0, 0}, "117440"},
+		{"input.txt", part2, []int64{0, 0, 0}, "164542125272765"},
 	}
 
 	for _, c := range cases {
@@ -30,3 +49,536 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestInstructionSet(t *testing.T) {
+	// a fourth register, addressed by combo operand 7, and an opcode to set it
+	isa, err := NewInstructionSet([]string{"A", "B", "C", "D"}, append(slices.Clone(Chronospatial.Opcodes), Opcode{"dst", comboOperand, "D = {}", func(s *State, operand int) {
+		s.Registers[3] = s.ResolveComboOperand(operand)
+		s.Pointer += 2
+	}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	isa.ParseProgram = input.Ints
+	isa.FormatOutput = func(output []int) string { return fmt.Sprint(output) }
+
+	source := "Register D: 9\nRegister A: 5\n\nProgram: 5 7 8 4 5 7 8 1 5 7\n"
+
+	registers, program, err := isa.Read(strings.NewReader(source))
+	if err != nil || !slices.Equal(registers, []int64{5, 0, 0, 9}) || len(program) != 10 {
+		t.Fatalf("unexpected input %v %v (%v)", registers, program, err)
+	}
+
+	state, err := isa.NewState(registers, program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace, _ := state.Trace(0)
+	state.Execute()
+
+	if output := state.RenderOutput(); output != "[1 5 1]" || state.Registers[3] != 1 {
+		t.Errorf("expected [1 5 1] with D=1, got %s with D=%d", output, state.Registers[3])
+	}
+
+	if entry := trace[1].String(); !strings.Contains(entry, "dst A") || !strings.Contains(entry, "D=5") {
+		t.Errorf("expected the trace to show dst and D, got %q", entry)
+	}
+
+	// the puzzle's machine only has three registers
+	if _, _, err := Chronospatial.Read(strings.NewReader(source)); err == nil {
+		t.Errorf("expected an error for register D")
+	}
+	if _, err := NewState(registers, program); err == nil {
+		t.Errorf("expected an error for four register values")
+	}
+
+	// a State only has room for MaxRegisters
+	if _, err := NewInstructionSet(strings.Split("ABCDEFGHI", ""), Chronospatial.Opcodes); err == nil {
+		t.Errorf("expected an error for nine registers")
+	}
+	tooBig := &InstructionSet{Registers: strings.Split("ABCDEFGHI", ""), Opcodes: Chronospatial.Opcodes}
+	if _, err := tooBig.NewState(nil, program); err == nil {
+		t.Errorf("expected an error for a State with nine registers")
+	}
+
+	// the assembler and disassembler read the instruction set's opcodes
+	listing := isa.Disassemble(program)
+	if !strings.Contains(listing, "dst A    ; D = A") {
+		t.Errorf("expected dst A in:\n%s", listing)
+	}
+	if roundTrip, err := isa.Assemble(listing); err != nil || !slices.Equal(roundTrip, program) {
+		t.Errorf("expected %v, got %v (%v)", program, roundTrip, err)
+	}
+	if assembled, err := isa.Assemble("out D"); err != nil || !slices.Equal(assembled, []int{5, 7}) {
+		t.Errorf("expected out D to assemble to [5 7], got %v (%v)", assembled, err)
+	}
+
+	// but only the puzzle's opcodes can be compiled or decompiled
+	if _, err := isa.Compile(program); !errors.Is(err, ErrUnsupportedISA) {
+		t.Errorf("expected ErrUnsupportedISA from Compile, got %v", err)
+	}
+	if _, err := isa.Decompile(program); !errors.Is(err, ErrUnsupportedISA) {
+		t.Errorf("expected ErrUnsupportedISA from Decompile, got %v", err)
+	}
+
+	// a fifth register needs combo operand 8, so operands grow to 4 bits
+	wide, err := NewInstructionSet([]string{"A", "B", "C", "D", "E"}, append(slices.Clone(Chronospatial.Opcodes), Opcode{"est", comboOperand, "E = {}", func(s *State, operand int) {
+		s.Registers[4] = s.ResolveComboOperand(operand)
+		s.Pointer += 2
+	}}))
+	if err != nil || wide.OperandBits != 4 {
+		t.Fatalf("expected 4-bit operands, got %v (%v)", wide, err)
+	}
+
+	program, err = wide.Assemble("top: est A\nadv 1\nout E\nbxl 15\njnz top")
+	if err != nil || !slices.Equal(program, []int{8, 4, 0, 1, 5, 8, 1, 15, 3, 0}) {
+		t.Fatalf("unexpected program %v (%v)", program, err)
+	}
+	if roundTrip, err := wide.Assemble(wide.Disassemble(program)); err != nil || !slices.Equal(roundTrip, program) {
+		t.Errorf("expected %v, got %v (%v)\n%s", program, roundTrip, err, wide.Disassemble(program))
+	}
+	if _, err := wide.Assemble("out 9"); err == nil || !strings.Contains(err.Error(), "combo operand 9 is reserved") {
+		t.Errorf("expected combo operand 9 to be reserved, got %v", err)
+	}
+	if _, err := wide.Assemble("bxl 16"); err == nil || !strings.Contains(err.Error(), "not a 4-bit value") {
+		t.Errorf("expected 16 to be too wide, got %v", err)
+	}
+
+	state, err = wide.NewState([]int64{6}, program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Execute()
+	if output := state.RenderOutput(); output != "6,3,1" || state.Registers[4] != 1 {
+		t.Errorf("expected 6,3,1 with E=1, got %s with E=%d", output, state.Registers[4])
+	}
+
+	// without the wider operands, E can't be named
+	narrow := &InstructionSet{Registers: wide.Registers, Opcodes: wide.Opcodes}
+	if err := narrow.Validate(); err == nil {
+		t.Errorf("expected an error for five registers with 3-bit operands")
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	_, program := readInput("input_small_adv.txt")
+	expected := strings.Join([]string{
+		"L0:",
+		"    0: adv 1    ; A = A >> 1",
+		"    2: out A    ; out(A % 8)",
+		"    4: jnz L0   ; if A != 0 goto L0 (loop)",
+		"",
+	}, "\n")
+
+	if listing := Disassemble(program); listing != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, listing)
+	}
+
+	listing := Disassemble([]int{5, 7, 4, 0, 3, 8, 3, 1, 2})
+	for _, line := range []string{"out 7    ; invalid instruction", "bxc      ; B = B ^ C", "jnz 8    ; invalid instruction", "jnz 1    ; if A != 0 goto 1 (misaligned", "bst      ; missing operand"} {
+		if !strings.Contains(listing, line) {
+			t.Errorf("expected %q in:\n%s", line, listing)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	// the adv fixture, written out by hand
+	source := `
+		; halve A until it runs out, printing as we go
+		loop:
+			adv 1      ; A = A >> 1
+			out A
+			jnz loop
+	`
+
+	_, expected := readInput("input_small_adv.txt")
+	program, err := Assemble(source)
+	if err != nil || !Compare(program, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, program, err)
+	}
+
+	// everything the disassembler prints should assemble back to the same program
+	fixtures := []string{"input.txt", "input_small.txt", "input_small_adv.txt", "input_small_bst.txt", "input_small_bxc.txt", "input_small_bxl.txt", "input_small_out.txt", "input_small_quine.txt"}
+	for _, fileName := range fixtures {
+		_, program := readInput(fileName)
+		roundTrip, err := Assemble(Disassemble(program))
+
+		if err != nil || !Compare(roundTrip, program) {
+			t.Errorf("%s: expected %v, got %v (%v)", fileName, program, roundTrip, err)
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	cases := []struct {
+		source string
+		errors []string
+	}{
+		{"adv 7", []string{"line 1: adv: combo operand 7 is reserved"}},
+		{"bxl 1\nbxl A", []string{"line 2: bxl: invalid operand \"A\""}},
+		{"out 8\nfoo 1\nbst", []string{"line 1: out: operand 8 is not a 3-bit value", "line 2: unknown instruction \"foo\"", "line 3: bst is missing its operand"}},
+		{"jnz nowhere", []string{"line 1: undefined label \"nowhere\""}},
+		{"a:\na:", []string{"line 2: duplicate label \"a\""}},
+		{"out A\n4: out B", []string{"line 2: address 4 does not match actual address 2"}},
+		{"bxc\nbxc\nbxc\nbxc\nend:\njnz end", []string{"line 6: label \"end\" is at address 8, past the 3-bit jump range"}},
+	}
+
+	for _, c := range cases {
+		_, err := Assemble(c.source)
+		if err == nil {
+			t.Errorf("%q: expected errors %v", c.source, c.errors)
+			continue
+		}
+
+		if got := strings.Split(err.Error(), "\n"); !slices.Equal(got, c.errors) {
+			t.Errorf("%q: expected errors %q, got %q", c.source, c.errors, got)
+		}
+	}
+}
+
+func TestDebugger(t *testing.T) {
+	registers, program := readInput("input.txt")
+	state, _ := NewState(registers, program)
+
+	// the trace should end in the same place as a normal run
+	trace, halted := state.Trace(0)
+	_, expectedOutput := Part1(registers, program)
+	last := trace[len(trace)-1]
+	if !halted || last.OutputCount != len(strings.Split(expectedOutput, ",")) || last.Registers[registerA] != 0 || last.Pointer != 14 {
+		t.Errorf("unexpected final trace entry %v", last)
+	}
+
+	// a program that jumps back to itself forever is cut off
+	looping, _ := NewState([]int64{1, 0, 0}, []int{3, 0})
+	if trace, halted := looping.Trace(100); halted || len(trace) != 100 {
+		t.Errorf("expected a truncated trace of 100 steps, got %d (halted: %v)", len(trace), halted)
+	}
+
+	debugger := NewDebugger(state)
+	debugger.Breakpoints[12] = true
+
+	if reason := debugger.Continue(0); reason != StoppedBreakpoint || debugger.Pointer != 12 || len(debugger.Output) != 0 {
+		t.Errorf("expected to stop before the first out, got %s at %d", reason, debugger.Pointer)
+	}
+
+	if reason := debugger.Continue(0); reason != StoppedBreakpoint || len(debugger.Output) != 1 {
+		t.Errorf("expected to stop before the second out, got %s with output %v", reason, debugger.Output)
+	}
+
+	debugger.Reset()
+	debugger.Breakpoints = map[int]bool{}
+	debugger.OutputBreak = 3
+
+	if reason := debugger.Continue(0); reason != StoppedOutput || len(debugger.Output) != 3 {
+		t.Errorf("expected to stop after 3 outputs, got %s with output %v", reason, debugger.Output)
+	}
+
+	debugger.Reset()
+	debugger.OutputBreak = 0
+	debugger.Watches["C"] = true
+
+	if reason := debugger.Continue(0); reason != StoppedWatchpoint || debugger.Pointer != 6 {
+		t.Errorf("expected the cdv to trip the watchpoint, got %s at %d", reason, debugger.Pointer)
+	}
+
+	debugger.Reset()
+	debugger.Watches = map[string]bool{}
+
+	if reason := debugger.Continue(5); reason != StoppedStepLimit || debugger.Steps != 5 {
+		t.Errorf("expected to stop after 5 steps, got %s after %d", reason, debugger.Steps)
+	}
+
+	if reason := debugger.Continue(0); reason != StoppedHalted || debugger.RenderOutput() != expectedOutput {
+		t.Errorf("expected to halt with %s, got %s with %s", expectedOutput, reason, debugger.RenderOutput())
+	}
+}
+
+func TestRunDebugger(t *testing.T) {
+	registers, program := readInput("input.txt")
+	state, _ := NewState(registers, program)
+
+	var out strings.Builder
+	RunDebugger(NewDebugger(state), strings.NewReader("s 3\nt -1\nt 0\nt 2\nq\n"), &out)
+
+	if count := strings.Count(out.String(), "usage: trace [n] with n at least 1"); count != 2 {
+		t.Errorf("expected t -1 and t 0 to print the usage, got:\n%s", out.String())
+	}
+	if count := strings.Count(out.String(), "outputs="); count != 2 {
+		t.Errorf("expected t 2 to print 2 entries, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	RunDebugger(NewDebugger(state), strings.NewReader("s -5\ns 0\ns 9999999999\nq\n"), &out)
+
+	if count := strings.Count(out.String(), "usage: step [n] with n at least 1"); count != 2 {
+		t.Errorf("expected s -5 and s 0 to print the usage, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), fmt.Sprintf("stepping at most %d instructions", StepLimit)) || !strings.Contains(out.String(), "stopped (halted)") {
+		t.Errorf("expected s 9999999999 to be capped and run to the end, got:\n%s", out.String())
+	}
+
+	// a bad opcode or combo operand stops the session instead of crashing it
+	for _, program := range [][]int{{0, 1, 8, 0}, {0, 1, 2, 7}} {
+		bad, _ := NewState([]int64{8, 0, 0}, program)
+		debugger := NewDebugger(bad)
+
+		out.Reset()
+		RunDebugger(debugger, strings.NewReader("c\nr\nq\n"), &out)
+
+		if !errors.Is(debugger.Err, ErrInvalidInstruction) || debugger.Pointer != 2 || debugger.Steps != 1 {
+			t.Errorf("%v: expected an invalid instruction at 2 after 1 step, got %v at %d after %d", program, debugger.Err, debugger.Pointer, debugger.Steps)
+		}
+		if !strings.Contains(out.String(), "stopped (invalid instruction) at 2") || !strings.Contains(out.String(), "pointer=2") {
+			t.Errorf("%v: expected the session to carry on after the bad instruction, got:\n%s", program, out.String())
+		}
+	}
+}
+
+func TestSolveQuine(t *testing.T) {
+	cases := []struct {
+		fileName string
+		expected int64
+	}{
+		{"input_small_quine.txt", 117440},
+		{"input.txt", 164542125272765},
+	}
+
+	for _, c := range cases {
+		registers, program := readInput(c.fileName)
+		result, err := SolveQuine(registers, program)
+		if err != nil || result != c.expected {
+			t.Errorf("%s: expected %d, got %d (%v)", c.fileName, c.expected, result, err)
+		}
+	}
+
+	unsupported := []string{
+		"adv 1\nout A\njnz 0",               // shifts by 1
+		"out B\nadv 3\njnz 0",               // reads B before setting it
+		"bst A\nout B\njnz 0",               // never shifts
+		"adv 3\nout A\nout A\njnz 0",        // two outputs per pass
+		"bst A\nadv 3\nout B\njnz 2",        // loop doesn't restart at 0
+		"bst A\nadv 3\njnz 0\nout B\njnz 0", // extra jump
+	}
+
+	for _, source := range unsupported {
+		program, err := Assemble(source)
+		if err != nil {
+			t.Fatalf("%q: %v", source, err)
+		}
+
+		if _, err := SolveQuine([]int64{0, 0, 0}, program); !errors.Is(err, ErrNotShiftLoop) {
+			t.Errorf("%q: expected ErrNotShiftLoop, got %v", source, err)
+		}
+
+		// Part2 hands the error back rather than falling back to a search
+		if registers, output, err := Part2([]int64{0, 0, 0}, program); !errors.Is(err, ErrNotShiftLoop) || registers != nil || output != "" {
+			t.Errorf("%q: expected Part2 to return ErrNotShiftLoop, got %v, %q, %v", source, registers, output, err)
+		}
+	}
+
+	_, program := readInput("input_small_quine.txt")
+	for _, registers := range [][]int64{nil, {0}, {0, 0}} {
+		if _, err := SolveQuine(registers, program); err == nil {
+			t.Errorf("%v: expected an error for too few registers", registers)
+		}
+	}
+
+	// fits the pattern, but always prints 7 so it can never match itself
+	program, _ = Assemble("adv 3\nbst 0\nbxl 7\nout B\njnz 0")
+	if _, err := SolveQuine([]int64{0, 0, 0}, program); !errors.Is(err, ErrNoQuine) {
+		t.Errorf("expected ErrNoQuine, got %v", err)
+	}
+}
+
+func TestDecompile(t *testing.T) {
+	_, program := readInput("input.txt")
+
+	decompiled, err := Decompile(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"B = A & 7", "B ^= 1", "C = A >> B", "B ^= 5", "A >>= 3", "B ^= C", "out(B & 7)"}
+	if !decompiled.Loop || !slices.Equal(decompiled.Statements, expected) {
+		t.Errorf("expected loop over %q, got %v %q", expected, decompiled.Loop, decompiled.Statements)
+	}
+
+	if shift := decompiled.Shift(); shift != 3 {
+		t.Errorf("expected a shift of 3, got %d", shift)
+	}
+
+	// the symbolic output has to agree with running the first pass
+	random := rand.New(rand.NewPCG(36, 2024))
+	for range 1000 {
+		a := random.Int64N(1 << 48)
+
+		state, _ := NewState([]int64{a}, program)
+		debugger := NewDebugger(state)
+		debugger.OutputBreak = 1
+		debugger.Continue(0)
+
+		if got := decompiled.Outputs[0].Eval(a, 0, 0); got != int64(debugger.Output[0]) {
+			t.Fatalf("A=%d: %s gave %d, the program printed %d", a, decompiled.Outputs[0], got, debugger.Output[0])
+		}
+	}
+
+	cases := []struct {
+		source     string
+		statements []string
+		output     string
+	}{
+		// consecutive constant XORs merge, or vanish when they cancel
+		{"bst A\nbxl 1\nbxl 5\nout B\nadv 3\njnz 0", []string{"B = A & 7", "B ^= 4", "out(B & 7)", "A >>= 3"}, "(A & 7) ^ 4"},
+		{"bst A\nbxl 6\nbxl 6\nout B\nadv 3\njnz 0", []string{"B = A & 7", "out(B & 7)", "A >>= 3"}, "A & 7"},
+		{"adv 1\nadv 2\nout 3\njnz 0", []string{"A >>= 1", "A >>= 2", "out(3)"}, "3"},
+	}
+
+	for _, c := range cases {
+		program, _ := Assemble(c.source)
+
+		decompiled, err := Decompile(program)
+		if err != nil {
+			t.Fatalf("%q: %v", c.source, err)
+		}
+
+		if !slices.Equal(decompiled.Statements, c.statements) || decompiled.Outputs[0].String() != c.output {
+			t.Errorf("%q: expected %q with output %s, got %q with output %s", c.source, c.statements, c.output, decompiled.Statements, decompiled.Outputs[0])
+		}
+	}
+
+	// a mask can only be dropped when it keeps every bit the value might have
+	masks := []struct {
+		expr     Expr
+		expected string
+	}{
+		{BinOp{Op: "&", Left: BinOp{Op: "&", Left: Reg("A"), Right: Const(3)}, Right: Const(7)}, "A & 3"},
+		{BinOp{Op: "&", Left: BinOp{Op: "&", Left: Reg("A"), Right: Const(3)}, Right: Const(4)}, "(A & 3) & 4"},
+		{BinOp{Op: "&", Left: BinOp{Op: "&", Left: Reg("A"), Right: Const(7)}, Right: Const(5)}, "(A & 7) & 5"},
+	}
+
+	for _, m := range masks {
+		simplified := simplify(m.expr)
+		if simplified.String() != m.expected {
+			t.Errorf("%s: expected %s, got %s", m.expr, m.expected, simplified)
+		}
+		for a := range int64(16) {
+			if got, want := simplified.Eval(a, 0, 0), m.expr.Eval(a, 0, 0); got != want {
+				t.Errorf("%s with A=%d: simplified to %s which gives %d, expected %d", m.expr, a, simplified, got, want)
+			}
+		}
+	}
+
+	// a jump inside the pass falls back to gotos with no summary
+	program, _ = Assemble("top: bst A\nadv 3\njnz top\nout B\njnz 0")
+	decompiled, _ = Decompile(program)
+	if decompiled.Loop || decompiled.Registers != nil || !slices.Contains(decompiled.Statements, "if A != 0 goto L0") {
+		t.Errorf("expected unstructured listing, got %q", decompiled.Statements)
+	}
+
+	// B is read before it's set, but the value is thrown away, so each pass
+	// still only depends on A
+	program, _ = Assemble("bxl 3\nbst A\nout B\nadv 3\njnz 0")
+	if err := CheckShiftLoop(program); err != nil {
+		t.Errorf("expected a shift loop, got %v", err)
+	}
+}
+
+func TestCompiledMatchesInterpreter(t *testing.T) {
+	random := rand.New(rand.NewPCG(17, 2024))
+	checked := 0
+
+	for range 5000 {
+		// random valid programs with aligned jumps
+		program := []int{}
+		for range 2 + random.IntN(8) {
+			opcode := random.IntN(8)
+			operand := random.IntN(8)
+
+			if Chronospatial.Opcodes[opcode].Operand == comboOperand && operand == 7 {
+				operand = random.IntN(7)
+			}
+			if opcode == 3 {
+				operand = 2 * random.IntN(4)
+			}
+
+			program = append(program, opcode, operand)
+		}
+
+		initial := []int64{random.Int64N(1 << 62), random.Int64N(1 << 62), random.Int64N(1 << 62)}
+		state, _ := NewState(initial, program)
+
+		// not every random program halts
+		debugger := NewDebugger(state)
+		if debugger.Continue(10000) != StoppedHalted {
+			continue
+		}
+
+		compiled, err := Compile(program)
+		if err != nil {
+			t.Fatalf("%v: %v", program, err)
+		}
+
+		registers, output := compiled.Run(initial[0], initial[1], initial[2], nil)
+		expected := debugger.RegisterValues()
+
+		if !slices.Equal(registers, expected) || !slices.Equal(output, debugger.Output) {
+			t.Errorf("%v: interpreter gave %v %v, compiled gave %v %v", program, expected, debugger.Output, registers, output)
+		}
+
+		if !compiled.Matches(initial[0], initial[1], initial[2], debugger.Output) {
+			t.Errorf("%v: Matches rejected the interpreter's output", program)
+		}
+
+		checked++
+	}
+
+	if checked < 1000 {
+		t.Errorf("only %d random programs halted", checked)
+	}
+
+	// values past 2^53 used to lose precision in adv
+	compiled, _ := Compile([]int{0, 1, 5, 4, 3, 0})
+	a := int64(1)<<60 + 5
+	_, output := compiled.Run(a, 0, 0, nil)
+	if output[0] != 2 {
+		t.Errorf("expected the first output of %d to be 2, got %d", a, output[0])
+	}
+
+	for _, program := range [][]int{{0, 7}, {3, 1}, {1}} {
+		if _, err := Compile(program); err == nil {
+			t.Errorf("%v: expected a compile error", program)
+		}
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	registers, program := readInput("input.txt")
+	compiled, _ := Compile(program)
+
+	b.Run("interpreter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for a := int64(1 << 45); a < 1<<45+4096; a++ {
+				state, _ := NewState([]int64{a, registers[1], registers[2]}, program)
+				state.Execute()
+			}
+		}
+	})
+
+	b.Run("compiled", func(b *testing.B) {
+		out := make([]int, 0, 32)
+		for i := 0; i < b.N; i++ {
+			for a := int64(1 << 45); a < 1<<45+4096; a++ {
+				_, out = compiled.Run(a, registers[1], registers[2], out)
+			}
+		}
+	})
+
+	b.Run("compiled-matches", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for a := int64(1 << 45); a < 1<<45+4096; a++ {
+				compiled.Matches(a, registers[1], registers[2], program)
+			}
+		}
+	})
+}
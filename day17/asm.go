@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var labelPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type pendingJump struct {
+	line    int
+	address int
+	label   string
+}
+
+// Assemble turns mnemonic source into a program for the puzzle's machine
+func Assemble(source string) ([]int, error) {
+	return Chronospatial.Assemble(source)
+}
+
+// Assemble turns mnemonic source into a program. Each line holds at most one
+// instruction, optionally preceded by "label:" and followed by a "; comment".
+// A leading "12:" is treated as an address check, which lets the output of
+// Disassemble be assembled again. Every error is reported with its line.
+func (isa *InstructionSet) Assemble(source string) ([]int, error) {
+	program := []int{}
+	labels := map[string]int{}
+	jumps := []pendingJump{}
+	errs := []error{}
+
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...)))
+	}
+
+	for i, text := range strings.Split(source, "\n") {
+		line := i + 1
+
+		if comment := strings.IndexAny(text, ";#"); comment >= 0 {
+			text = text[:comment]
+		}
+		text = strings.TrimSpace(text)
+
+		// strip any labels and address checks
+		for {
+			colon := strings.Index(text, ":")
+			if colon < 0 {
+				break
+			}
+
+			name := strings.TrimSpace(text[:colon])
+			text = strings.TrimSpace(text[colon+1:])
+
+			if address, err := strconv.Atoi(name); err == nil {
+				if address != len(program) {
+					fail(line, "address %d does not match actual address %d", address, len(program))
+				}
+				continue
+			}
+
+			if !labelPattern.MatchString(name) {
+				fail(line, "invalid label %q", name)
+				continue
+			}
+
+			if _, ok := labels[name]; ok {
+				fail(line, "duplicate label %q", name)
+				continue
+			}
+
+			labels[name] = len(program)
+		}
+
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		opcode, ok := isa.Lookup(fields[0])
+		if !ok {
+			fail(line, "unknown instruction %q", fields[0])
+			continue
+		}
+
+		operandText := ""
+		switch {
+		case len(fields) == 2:
+			operandText = fields[1]
+		case len(fields) > 2:
+			fail(line, "%s takes one operand, got %d", fields[0], len(fields)-1)
+			continue
+		case isa.Opcodes[opcode].Operand == ignoredOperand:
+			operandText = "0"
+		default:
+			fail(line, "%s is missing its operand", fields[0])
+			continue
+		}
+
+		operand, err := isa.parseOperand(isa.Opcodes[opcode].Operand, operandText)
+
+		if err != nil && isa.Opcodes[opcode].Operand == jumpOperand && labelPattern.MatchString(operandText) {
+			jumps = append(jumps, pendingJump{line: line, address: len(program) + 1, label: operandText})
+			operand, err = 0, nil
+		}
+
+		if err != nil {
+			fail(line, "%s: %v", fields[0], err)
+			continue
+		}
+
+		program = append(program, opcode, operand)
+	}
+
+	for _, jump := range jumps {
+		target, ok := labels[jump.label]
+
+		switch {
+		case !ok:
+			fail(jump.line, "undefined label %q", jump.label)
+		case target >= isa.operandLimit():
+			fail(jump.line, "label %q is at address %d, past the %d-bit jump range", jump.label, target, isa.operandBits())
+		default:
+			program[jump.address] = target
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return program, nil
+}
+
+func (isa *InstructionSet) parseOperand(kind operandKind, text string) (int, error) {
+	if kind == comboOperand {
+		if register, ok := isa.Register(text); ok {
+			return register + 4, nil
+		}
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid operand %q", text)
+	}
+
+	if value < 0 || value >= isa.operandLimit() {
+		return 0, fmt.Errorf("operand %d is not a %d-bit value", value, isa.operandBits())
+	}
+
+	if _, ok := isa.ComboName(value); kind == comboOperand && !ok {
+		return 0, fmt.Errorf("combo operand %d is reserved", value)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+)
+
+// machine keeps the registers next to the literal combo operands, so a combo
+// operand is just an index: 0-3 are themselves and 4-6 are A, B and C
+type machine struct {
+	combo  [7]int64
+	output []int
+}
+
+const (
+	regA = 4
+	regB = 5
+	regC = 6
+)
+
+func newMachine(a, b, c int64, out []int) machine {
+	return machine{combo: [7]int64{0, 1, 2, 3, a, b, c}, output: out}
+}
+
+// op runs one instruction and returns the index of the next one
+type op func(m *machine) int
+
+// Compiled is a program translated into a chain of closures, one per
+// instruction, with every operand resolved ahead of time
+type Compiled struct {
+	ops []op
+}
+
+// Compile translates a program for fast repeated execution. It rejects
+// programs the interpreter would choke on: reserved combo operands, a missing
+// final operand, and jumps into the middle of an instruction.
+func Compile(program []int) (*Compiled, error) {
+	return Chronospatial.Compile(program)
+}
+
+// Compile translates a program for the instruction set. Only the puzzle's
+// opcodes have compiled forms, so any other instruction set is rejected with
+// ErrUnsupportedISA.
+func (isa *InstructionSet) Compile(program []int) (*Compiled, error) {
+	if isa != Chronospatial {
+		return nil, ErrUnsupportedISA
+	}
+
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("program has %d values, the last opcode has no operand", len(program))
+	}
+
+	instructions := isa.Decode(program)
+	halt := len(instructions)
+	compiled := &Compiled{ops: make([]op, len(instructions))}
+
+	for i, instruction := range instructions {
+		if !instruction.Valid() {
+			return nil, fmt.Errorf("invalid instruction %s at %d", instruction, instruction.Address)
+		}
+
+		next := i + 1
+		operand := instruction.Operand
+		literal := int64(operand)
+
+		switch instruction.Opcode {
+		case 0:
+			compiled.ops[i] = func(m *machine) int { m.combo[regA] = divPow2(m.combo[regA], m.combo[operand]); return next }
+		case 1:
+			compiled.ops[i] = func(m *machine) int { m.combo[regB] ^= literal; return next }
+		case 2:
+			compiled.ops[i] = func(m *machine) int { m.combo[regB] = m.combo[operand] % 8; return next }
+		case 3:
+			if operand%2 != 0 {
+				return nil, fmt.Errorf("jnz at %d jumps to odd address %d", instruction.Address, operand)
+			}
+
+			target := min(operand/2, halt)
+			compiled.ops[i] = func(m *machine) int {
+				if m.combo[regA] != 0 {
+					return target
+				}
+				return next
+			}
+		case 4:
+			compiled.ops[i] = func(m *machine) int { m.combo[regB] ^= m.combo[regC]; return next }
+		case 5:
+			compiled.ops[i] = func(m *machine) int { m.output = append(m.output, int(m.combo[operand]%8)); return next }
+		case 6:
+			compiled.ops[i] = func(m *machine) int { m.combo[regB] = divPow2(m.combo[regA], m.combo[operand]); return next }
+		case 7:
+			compiled.ops[i] = func(m *machine) int { m.combo[regC] = divPow2(m.combo[regA], m.combo[operand]); return next }
+		}
+	}
+
+	return compiled, nil
+}
+
+// Run executes the program from the start and returns the final registers
+// and output. Output is appended to out, so a caller can reuse one buffer.
+func (c *Compiled) Run(a, b, cReg int64, out []int) ([]int64, []int) {
+	m := newMachine(a, b, cReg, out[:0])
+
+	for pc := 0; pc < len(c.ops); {
+		pc = c.ops[pc](&m)
+	}
+
+	return []int64{m.combo[regA], m.combo[regB], m.combo[regC]}, m.output
+}
+
+// Matches reports whether the program's output with the given registers is
+// exactly expected, stopping as soon as a value differs
+func (c *Compiled) Matches(a, b, cReg int64, expected []int) bool {
+	m := newMachine(a, b, cReg, nil)
+	checked := 0
+
+	for pc := 0; pc < len(c.ops); {
+		pc = c.ops[pc](&m)
+
+		for ; checked < len(m.output); checked++ {
+			if checked >= len(expected) || m.output[checked] != expected[checked] {
+				return false
+			}
+		}
+	}
+
+	return len(m.output) == len(expected)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TraceEntry records the machine state after one instruction has run
+type TraceEntry struct {
+	Step    int
+	Pointer int
+	Opcode  int
+	Operand int
+
+	ISA       *InstructionSet
+	Registers []int64
+
+	// OutputCount is how many values the program has printed so far
+	OutputCount int
+}
+
+func (t TraceEntry) String() string {
+	instruction := Instruction{Address: t.Pointer, Opcode: t.Opcode, Operand: t.Operand, ISA: t.ISA}
+
+	registers := ""
+	for i, name := range instruction.instructionSet().Registers {
+		registers += fmt.Sprintf("%s=%-16d ", name, t.Registers[i])
+	}
+
+	return fmt.Sprintf("%6d  %3d  %-6s  %soutputs=%d", t.Step, t.Pointer, instruction, registers, t.OutputCount)
+}
+
+// StepLimit is how many instructions the debugger and Trace run before giving
+// up on a program that might never halt
+const StepLimit = 1_000_000
+
+// Trace runs a copy of the program and returns one entry per instruction, up
+// to maxSteps of them (StepLimit if maxSteps isn't positive). It reports
+// whether the program halted; if it didn't, the trace is truncated.
+func (s State) Trace(maxSteps int) ([]TraceEntry, bool) {
+	if maxSteps <= 0 {
+		maxSteps = StepLimit
+	}
+
+	debugger := NewDebugger(s)
+	debugger.Tracing = true
+	reason := debugger.Continue(maxSteps)
+
+	return debugger.Trace, reason == StoppedHalted
+}
+
+type StopReason int
+
+const (
+	StoppedHalted StopReason = iota
+	StoppedStep
+	StoppedBreakpoint
+	StoppedOutput
+	StoppedWatchpoint
+	StoppedStepLimit
+	StoppedInvalid
+)
+
+func (r StopReason) String() string {
+	return []string{"halted", "step", "breakpoint", "output count", "watchpoint", "step limit", "invalid instruction"}[r]
+}
+
+// ErrInvalidInstruction is what the debugger stops with rather than running
+// an instruction that would panic
+var ErrInvalidInstruction = errors.New("invalid instruction")
+
+// CheckInstruction reports whether the instruction at the pointer can run: its
+// opcode has to exist, and a combo operand has to be a literal or a register
+func (s State) CheckInstruction() error {
+	code, operand := s.Program[s.Pointer], s.Program[s.Pointer+1]
+
+	opcode, ok := s.instructionSet().Opcode(code)
+	if !ok {
+		return fmt.Errorf("%w: opcode %d at %d", ErrInvalidInstruction, code, s.Pointer)
+	}
+
+	if opcode.Operand == comboOperand {
+		if _, ok := s.instructionSet().ComboName(operand); !ok {
+			return fmt.Errorf("%w: combo operand %d at %d", ErrInvalidInstruction, operand, s.Pointer)
+		}
+	}
+
+	return nil
+}
+
+// Debugger steps through a program, stopping at breakpoints on the
+// instruction pointer, when the output reaches a given length, or when a
+// watched register changes
+type Debugger struct {
+	State
+	initial State
+
+	Breakpoints map[int]bool
+	OutputBreak int
+	Watches     map[string]bool
+
+	Tracing bool
+	Trace   []TraceEntry
+	Steps   int
+
+	// Err is why the debugger last stopped with StoppedInvalid
+	Err error
+}
+
+func NewDebugger(s State) *Debugger {
+	d := &Debugger{Breakpoints: map[int]bool{}, Watches: map[string]bool{}}
+	d.initial = s
+	d.Reset()
+
+	return d
+}
+
+// Reset puts the machine back in the state it started in, keeping any
+// breakpoints and watches
+func (d *Debugger) Reset() {
+	d.State = d.initial
+	d.State.Output = slices.Clone(d.initial.Output)
+	d.Trace = nil
+	d.Steps = 0
+	d.Err = nil
+}
+
+// Register returns the value of a register by name
+func (d *Debugger) Register(name string) (int64, bool) {
+	register, ok := d.instructionSet().Register(name)
+	if !ok {
+		return 0, false
+	}
+
+	return d.Registers[register], true
+}
+
+// SetRegister changes the value of a register by name
+func (d *Debugger) SetRegister(name string, value int64) bool {
+	register, ok := d.instructionSet().Register(name)
+	if ok {
+		d.Registers[register] = value
+	}
+
+	return ok
+}
+
+// Step runs a single instruction and reports why it stopped. An instruction
+// that can't run stops the debugger with StoppedInvalid, leaving the machine
+// as it was and the reason in Err.
+func (d *Debugger) Step() StopReason {
+	if d.Halted() {
+		return StoppedHalted
+	}
+
+	if err := d.CheckInstruction(); err != nil {
+		d.Err = err
+		return StoppedInvalid
+	}
+
+	pointer := d.Pointer
+	before := d.Registers
+	outputs := len(d.Output)
+
+	d.State.Step()
+	d.Steps++
+
+	if d.Tracing {
+		d.Trace = append(d.Trace, TraceEntry{
+			Step:        d.Steps,
+			Pointer:     pointer,
+			Opcode:      d.Program[pointer],
+			Operand:     d.Program[pointer+1],
+			ISA:         d.ISA,
+			Registers:   d.RegisterValues(),
+			OutputCount: len(d.Output),
+		})
+	}
+
+	for i, name := range d.instructionSet().Registers {
+		if d.Watches[name] && before[i] != d.Registers[i] {
+			return StoppedWatchpoint
+		}
+	}
+
+	if d.OutputBreak > 0 && outputs < d.OutputBreak && len(d.Output) >= d.OutputBreak {
+		return StoppedOutput
+	}
+
+	if d.Halted() {
+		return StoppedHalted
+	}
+
+	if d.Breakpoints[d.Pointer] {
+		return StoppedBreakpoint
+	}
+
+	return StoppedStep
+}
+
+// Continue steps until something stops the program. A limit above zero caps
+// the number of instructions run, since not every program halts.
+func (d *Debugger) Continue(limit int) StopReason {
+	for i := 0; limit <= 0 || i < limit; i++ {
+		if reason := d.Step(); reason != StoppedStep {
+			return reason
+		}
+	}
+
+	return StoppedStepLimit
+}
+
+const debuggerHelp = `commands:
+  s, step [n]       run n instructions (default 1)
+  c, continue       run until a breakpoint, watchpoint or halt
+  b, break <addr>   toggle a breakpoint on an instruction address
+  o, output <n>     stop once the output has n values (0 clears it)
+  w, watch <reg>    toggle a watchpoint on a register
+  set <reg> <value> change a register
+  r, regs           show the registers and output
+  l, list           show the program with the pointer marked
+  t, trace [n]      show the last n trace entries (default 10)
+  reset             restart the program
+  q, quit           leave the debugger
+`
+
+// RunDebugger reads commands from in until it runs out or sees quit
+func RunDebugger(d *Debugger, in io.Reader, out io.Writer) {
+	d.Tracing = true
+	scanner := bufio.NewScanner(in)
+
+	showStop := func(reason StopReason) {
+		fmt.Fprintf(out, "stopped (%s) at %d after %d steps\n", reason, d.Pointer, d.Steps)
+		if reason == StoppedInvalid {
+			fmt.Fprintln(out, d.Err)
+		}
+	}
+
+	fmt.Fprint(out, "(day17) ")
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			fmt.Fprint(out, "(day17) ")
+			continue
+		}
+
+		arg := func(i int) (int64, bool) {
+			if i >= len(fields) {
+				return 0, false
+			}
+			value, err := strconv.ParseInt(fields[i], 10, 64)
+			if err != nil {
+				fmt.Fprintf(out, "invalid number %q\n", fields[i])
+				return 0, false
+			}
+			return value, true
+		}
+
+		switch fields[0] {
+		case "s", "step":
+			count := int64(1)
+			if len(fields) > 1 {
+				var ok bool
+				if count, ok = arg(1); !ok {
+					break
+				}
+			}
+			if count < 1 {
+				fmt.Fprintln(out, "usage: step [n] with n at least 1")
+				break
+			}
+			if count > StepLimit {
+				fmt.Fprintf(out, "stepping at most %d instructions\n", StepLimit)
+				count = StepLimit
+			}
+
+			reason := StoppedStep
+			for range count {
+				if reason = d.Step(); reason != StoppedStep {
+					break
+				}
+			}
+			showStop(reason)
+		case "c", "continue":
+			showStop(d.Continue(StepLimit))
+		case "b", "break":
+			if address, ok := arg(1); ok {
+				d.Breakpoints[int(address)] = !d.Breakpoints[int(address)]
+				fmt.Fprintf(out, "breakpoint at %d: %v\n", address, d.Breakpoints[int(address)])
+			}
+		case "o", "output":
+			if count, ok := arg(1); ok {
+				d.OutputBreak = int(count)
+				fmt.Fprintf(out, "stopping at %d outputs\n", count)
+			}
+		case "w", "watch":
+			if len(fields) < 2 {
+				fmt.Fprintln(out, "watch needs a register")
+				break
+			}
+			register, ok := d.instructionSet().Register(fields[1])
+			if !ok {
+				fmt.Fprintf(out, "unknown register %q\n", fields[1])
+				break
+			}
+			name := d.instructionSet().Registers[register]
+			d.Watches[name] = !d.Watches[name]
+			fmt.Fprintf(out, "watching %s: %v\n", name, d.Watches[name])
+		case "set":
+			value, ok := arg(2)
+			if !ok || !d.SetRegister(fields[1], value) {
+				fmt.Fprintf(out, "usage: set <%s> <value>\n", strings.Join(d.instructionSet().Registers, "|"))
+				break
+			}
+			fmt.Fprintf(out, "%s = %d\n", strings.ToUpper(fields[1]), value)
+		case "r", "regs":
+			fmt.Fprintf(out, "pointer=%d", d.Pointer)
+			for i, name := range d.instructionSet().Registers {
+				fmt.Fprintf(out, " %s=%d (0o%o)", name, d.Registers[i], d.Registers[i])
+			}
+			fmt.Fprintf(out, " output=%s\n", d.RenderOutput())
+		case "l", "list":
+			for _, line := range strings.SplitAfter(d.instructionSet().Disassemble(d.Program), "\n") {
+				marker := "  "
+				if strings.HasPrefix(line, fmt.Sprintf("  %3d:", d.Pointer)) {
+					marker = "=>"
+				}
+				if line != "" {
+					fmt.Fprint(out, marker+line)
+				}
+			}
+		case "t", "trace":
+			count := int64(10)
+			if len(fields) > 1 {
+				var ok bool
+				if count, ok = arg(1); !ok {
+					break
+				}
+			}
+			if count < 1 {
+				fmt.Fprintln(out, "usage: trace [n] with n at least 1")
+				break
+			}
+			start := max(0, len(d.Trace)-int(count))
+			for _, entry := range d.Trace[start:] {
+				fmt.Fprintln(out, entry)
+			}
+		case "reset":
+			d.Reset()
+			fmt.Fprintln(out, "reset")
+		case "q", "quit":
+			return
+		default:
+			fmt.Fprint(out, debuggerHelp)
+		}
+
+		fmt.Fprint(out, "(day17) ")
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+// Expr is a symbolic value built from the registers at the start of a pass
+// through the program
+type Expr interface {
+	String() string
+	Eval(a, b, c int64) int64
+}
+
+// Reg is the value a register held when the pass started
+type Reg string
+
+// Const is a literal value
+type Const int64
+
+// BinOp combines two expressions with ">>", "^" or "&"
+type BinOp struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+func (r Reg) String() string { return string(r) }
+
+func (r Reg) Eval(a, b, c int64) int64 {
+	switch r {
+	case "A":
+		return a
+	case "B":
+		return b
+	}
+
+	return c
+}
+
+func (k Const) String() string { return fmt.Sprint(int64(k)) }
+
+func (k Const) Eval(a, b, c int64) int64 { return int64(k) }
+
+func (o BinOp) String() string {
+	return fmt.Sprintf("%s %s %s", operandString(o.Left), o.Op, operandString(o.Right))
+}
+
+func operandString(e Expr) string {
+	if _, ok := e.(BinOp); ok {
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+func (o BinOp) Eval(a, b, c int64) int64 {
+	left, right := o.Left.Eval(a, b, c), o.Right.Eval(a, b, c)
+
+	switch o.Op {
+	case ">>":
+		return divPow2(left, right)
+	case "^":
+		return left ^ right
+	}
+
+	return left & right
+}
+
+// FreeRegisters lists the registers an expression depends on
+func FreeRegisters(e Expr) []string {
+	switch e := e.(type) {
+	case Reg:
+		return []string{string(e)}
+	case BinOp:
+		registers := append(FreeRegisters(e.Left), FreeRegisters(e.Right)...)
+		slices.Sort(registers)
+		return slices.Compact(registers)
+	}
+
+	return nil
+}
+
+// simplify folds constants, merges chained shifts and XORs with constants,
+// and drops masks that can't change anything
+func simplify(e Expr) Expr {
+	o, ok := e.(BinOp)
+	if !ok {
+		return e
+	}
+
+	left, right := simplify(o.Left), simplify(o.Right)
+	lk, leftConst := left.(Const)
+	rk, rightConst := right.(Const)
+
+	if leftConst && rightConst {
+		return Const(BinOp{Op: o.Op, Left: lk, Right: rk}.Eval(0, 0, 0))
+	}
+
+	inner, innerOp := left.(BinOp)
+	innerK, innerConst := Const(0), false
+	if innerOp {
+		innerK, innerConst = inner.Right.(Const)
+	}
+
+	switch o.Op {
+	case "^":
+		// keep constants on the right
+		if leftConst {
+			left, right, lk, rk, leftConst, rightConst = right, left, rk, lk, false, true
+			inner, innerOp = left.(BinOp)
+			if innerOp {
+				innerK, innerConst = inner.Right.(Const)
+			}
+		}
+
+		if rightConst && rk == 0 {
+			return left
+		}
+
+		if rightConst && innerOp && inner.Op == "^" && innerConst {
+			return simplify(BinOp{Op: "^", Left: inner.Left, Right: Const(innerK ^ rk)})
+		}
+	case ">>":
+		if rightConst && rk == 0 {
+			return left
+		}
+
+		if rightConst && innerOp && inner.Op == ">>" && innerConst {
+			return BinOp{Op: ">>", Left: inner.Left, Right: Const(innerK + rk)}
+		}
+	case "&":
+		// only a mask of all ones up to the highest bit can be dropped
+		if rightConst && rk&(rk+1) == 0 && maxValue(left) <= int64(rk) {
+			return left
+		}
+	}
+
+	return BinOp{Op: o.Op, Left: left, Right: right}
+}
+
+// maxValue is an upper bound for a non-negative expression
+func maxValue(e Expr) int64 {
+	switch e := e.(type) {
+	case Const:
+		return int64(e)
+	case BinOp:
+		switch e.Op {
+		case "&":
+			return min(maxValue(e.Left), maxValue(e.Right))
+		case "^":
+			// XOR can't set bits above the highest bit of either side
+			width := bits.Len64(uint64(max(maxValue(e.Left), maxValue(e.Right))))
+			if width < 63 {
+				return 1<<width - 1
+			}
+		}
+	}
+
+	return math.MaxInt64
+}
+
+// Decompiled is a program lifted into pseudocode, along with what one pass
+// through it does to the registers
+type Decompiled struct {
+	Statements []string
+
+	// Loop is true when the program is one do-while loop ending in jnz 0
+	Loop bool
+
+	// Outputs holds the value printed by each out in a pass, and Registers
+	// the values of A, B and C at the end of the pass. Both are nil when a
+	// jump inside the pass makes the control flow depend on the data.
+	Outputs   []Expr
+	Registers map[string]Expr
+}
+
+// Decompile symbolically executes one pass through a program
+func Decompile(program []int) (*Decompiled, error) {
+	return Chronospatial.Decompile(program)
+}
+
+// Decompile symbolically executes one pass through a program for the
+// instruction set. Only the puzzle's opcodes are understood, so any other
+// instruction set is rejected with ErrUnsupportedISA.
+func (isa *InstructionSet) Decompile(program []int) (*Decompiled, error) {
+	if isa != Chronospatial {
+		return nil, ErrUnsupportedISA
+	}
+
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("program has %d values, the last opcode has no operand", len(program))
+	}
+
+	instructions := isa.Decode(program)
+	labels := JumpLabels(instructions)
+
+	jumps := 0
+	for _, instruction := range instructions {
+		if instruction.Opcode == 3 {
+			jumps++
+		}
+	}
+
+	body := instructions
+	decompiled := &Decompiled{
+		Registers: map[string]Expr{"A": Reg("A"), "B": Reg("B"), "C": Reg("C")},
+	}
+
+	if last := len(instructions) - 1; jumps == 1 && instructions[last].Opcode == 3 && instructions[last].Operand == 0 {
+		decompiled.Loop = true
+		body = instructions[:last]
+		delete(labels, 0)
+	}
+
+	combo := func(operand int) Expr {
+		if operand >= 4 {
+			name, _ := isa.ComboName(operand)
+			return decompiled.Registers[name]
+		}
+		return Const(operand)
+	}
+
+	set := func(register string, value Expr) {
+		decompiled.Registers[register] = simplify(value)
+	}
+
+	for _, instruction := range body {
+		if !instruction.Valid() {
+			return nil, fmt.Errorf("invalid instruction %s at %d", instruction, instruction.Address)
+		}
+
+		if label, ok := labels[instruction.Address]; ok {
+			decompiled.Statements = append(decompiled.Statements, label+":")
+		}
+
+		operand := instruction.OperandText(labels)
+		masked := operand + " & 7"
+		if instruction.Operand < 4 {
+			masked = operand
+		}
+
+		statement := ""
+		a := decompiled.Registers["A"]
+
+		switch instruction.Opcode {
+		case 0:
+			statement = "A >>= " + operand
+			set("A", BinOp{Op: ">>", Left: a, Right: combo(instruction.Operand)})
+		case 1:
+			decompiled.Statements = appendXor(decompiled.Statements, "B", int64(instruction.Operand))
+			set("B", BinOp{Op: "^", Left: decompiled.Registers["B"], Right: Const(instruction.Operand)})
+		case 2:
+			statement = "B = " + masked
+			set("B", BinOp{Op: "&", Left: combo(instruction.Operand), Right: Const(7)})
+		case 3:
+			statement = "if A != 0 goto " + operand
+		case 4:
+			statement = "B ^= C"
+			set("B", BinOp{Op: "^", Left: decompiled.Registers["B"], Right: decompiled.Registers["C"]})
+		case 5:
+			statement = "out(" + masked + ")"
+			decompiled.Outputs = append(decompiled.Outputs, simplify(BinOp{Op: "&", Left: combo(instruction.Operand), Right: Const(7)}))
+		case 6:
+			statement = "B = A >> " + operand
+			set("B", BinOp{Op: ">>", Left: a, Right: combo(instruction.Operand)})
+		case 7:
+			statement = "C = A >> " + operand
+			set("C", BinOp{Op: ">>", Left: a, Right: combo(instruction.Operand)})
+		}
+
+		if statement != "" {
+			decompiled.Statements = append(decompiled.Statements, statement)
+		}
+	}
+
+	if jumps > 0 && !decompiled.Loop {
+		decompiled.Outputs = nil
+		decompiled.Registers = nil
+	}
+
+	return decompiled, nil
+}
+
+// appendXor merges "B ^= 1; B ^= 5" into "B ^= 4", and drops it entirely if
+// the constants cancel out
+func appendXor(statements []string, register string, value int64) []string {
+	prefix := register + " ^= "
+
+	if n := len(statements); n > 0 && strings.HasPrefix(statements[n-1], prefix) {
+		var previous int64
+		if _, err := fmt.Sscanf(statements[n-1], prefix+"%d", &previous); err == nil {
+			value ^= previous
+			statements = statements[:n-1]
+		}
+	}
+
+	if value == 0 {
+		return statements
+	}
+
+	return append(statements, fmt.Sprintf("%s%d", prefix, value))
+}
+
+// Shift returns how far each pass shifts A right, or -1 if the pass does
+// anything else to A
+func (d *Decompiled) Shift() int64 {
+	if d.Registers == nil {
+		return -1
+	}
+
+	shift, ok := d.Registers["A"].(BinOp)
+	if !ok || shift.Op != ">>" || shift.Left != Reg("A") {
+		return -1
+	}
+
+	amount, ok := shift.Right.(Const)
+	if !ok {
+		return -1
+	}
+
+	return int64(amount)
+}
+
+func (d *Decompiled) String() string {
+	var sb strings.Builder
+
+	indent := ""
+	if d.Loop {
+		sb.WriteString("do {\n")
+		indent = "    "
+	}
+
+	for _, statement := range d.Statements {
+		fmt.Fprintf(&sb, "%s%s\n", indent, statement)
+	}
+
+	if d.Loop {
+		sb.WriteString("} while A != 0\n")
+	}
+
+	if d.Registers != nil {
+		sb.WriteString("\n// each pass, in terms of the registers at the start of the pass\n")
+
+		for _, output := range d.Outputs {
+			fmt.Fprintf(&sb, "// out: %s\n", output)
+		}
+
+		for _, register := range []string{"A", "B", "C"} {
+			fmt.Fprintf(&sb, "// %s = %s\n", register, d.Registers[register])
+		}
+	}
+
+	return sb.String()
+}
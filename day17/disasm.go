@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type operandKind int
+
+const (
+	comboOperand operandKind = iota
+	literalOperand
+	jumpOperand
+	ignoredOperand
+)
+
+// Instruction is a single decoded opcode/operand pair
+type Instruction struct {
+	Address int
+	Opcode  int
+	Operand int
+
+	// ISA is the instruction set to read it with, nil for the puzzle's
+	ISA *InstructionSet
+}
+
+func (i Instruction) instructionSet() *InstructionSet {
+	if i.ISA == nil {
+		return Chronospatial
+	}
+
+	return i.ISA
+}
+
+func (i Instruction) Mnemonic() string {
+	opcode, ok := i.instructionSet().Opcode(i.Opcode)
+	if !ok {
+		return fmt.Sprintf("?%d", i.Opcode)
+	}
+
+	return opcode.Name
+}
+
+// Valid reports whether the opcode exists and the operand is allowed for it
+func (i Instruction) Valid() bool {
+	opcode, ok := i.instructionSet().Opcode(i.Opcode)
+	if !ok || i.Operand < 0 || i.Operand >= i.instructionSet().operandLimit() {
+		return false
+	}
+
+	_, ok = i.instructionSet().ComboName(i.Operand)
+
+	return opcode.Operand != comboOperand || ok
+}
+
+// OperandText renders the operand the way the assembler expects it, with
+// combo operands resolved to register names
+func (i Instruction) OperandText(labels map[int]string) string {
+	opcode, ok := i.instructionSet().Opcode(i.Opcode)
+	if !ok {
+		return strconv.Itoa(i.Operand)
+	}
+
+	switch opcode.Operand {
+	case comboOperand:
+		if name, ok := i.instructionSet().ComboName(i.Operand); ok {
+			return name
+		}
+	case jumpOperand:
+		if label, ok := labels[i.Operand]; ok {
+			return label
+		}
+	case ignoredOperand:
+		if i.Operand == 0 {
+			return ""
+		}
+	}
+
+	return strconv.Itoa(i.Operand)
+}
+
+// Effect describes what the instruction does in pseudocode
+func (i Instruction) Effect(labels map[int]string) string {
+	if !i.Valid() {
+		return "invalid instruction"
+	}
+
+	opcode, _ := i.instructionSet().Opcode(i.Opcode)
+
+	return strings.ReplaceAll(opcode.Effect, "{}", i.OperandText(labels))
+}
+
+func (i Instruction) String() string {
+	return strings.TrimSpace(i.Mnemonic() + " " + i.OperandText(nil))
+}
+
+// jump reports whether the instruction is a valid jump
+func (i Instruction) jump() bool {
+	opcode, ok := i.instructionSet().Opcode(i.Opcode)
+
+	return ok && opcode.Operand == jumpOperand && i.Valid()
+}
+
+// Decode splits a program for the puzzle's machine into instructions
+func Decode(program []int) []Instruction {
+	return Chronospatial.Decode(program)
+}
+
+// Decode splits a program into instructions. A trailing opcode without an
+// operand is returned with an operand of -1.
+func (isa *InstructionSet) Decode(program []int) []Instruction {
+	instructions := []Instruction{}
+
+	for pointer := 0; pointer < len(program); pointer += 2 {
+		operand := -1
+		if pointer+1 < len(program) {
+			operand = program[pointer+1]
+		}
+
+		instructions = append(instructions, Instruction{Address: pointer, Opcode: program[pointer], Operand: operand, ISA: isa})
+	}
+
+	return instructions
+}
+
+// JumpLabels names every jump target in address order, L0, L1, ... Odd targets
+// land on an operand, so they are left as plain numbers.
+func JumpLabels(instructions []Instruction) map[int]string {
+	targets := []int{}
+
+	for _, instruction := range instructions {
+		if instruction.jump() && instruction.Operand%2 == 0 {
+			targets = append(targets, instruction.Operand)
+		}
+	}
+
+	slices.Sort(targets)
+	targets = slices.Compact(targets)
+
+	labels := map[int]string{}
+	for i, target := range targets {
+		labels[target] = fmt.Sprintf("L%d", i)
+	}
+
+	return labels
+}
+
+// Disassemble renders a program for the puzzle's machine as an annotated
+// listing
+func Disassemble(program []int) string {
+	return Chronospatial.Disassemble(program)
+}
+
+// Disassemble renders a program as an annotated listing
+func (isa *InstructionSet) Disassemble(program []int) string {
+	instructions := isa.Decode(program)
+	labels := JumpLabels(instructions)
+
+	var sb strings.Builder
+
+	for _, instruction := range instructions {
+		if label, ok := labels[instruction.Address]; ok {
+			fmt.Fprintf(&sb, "%s:\n", label)
+		}
+
+		text := instruction.Mnemonic()
+		if operand := instruction.OperandText(labels); operand != "" {
+			text += " " + operand
+		}
+
+		comment := instruction.Effect(labels)
+
+		switch {
+		case instruction.Operand == -1:
+			text = instruction.Mnemonic()
+			comment = "missing operand, the program halts here"
+		case instruction.jump() && instruction.Operand%2 == 1:
+			comment += " (misaligned, reads operands as opcodes)"
+		case instruction.jump() && instruction.Operand <= instruction.Address:
+			comment += " (loop)"
+		}
+
+		fmt.Fprintf(&sb, "  %3d: %-8s ; %s\n", instruction.Address, text, comment)
+	}
+
+	// jumping past the end halts, but the label still needs to appear
+	for address := len(program); address < isa.operandLimit(); address++ {
+		if label, ok := labels[address]; ok {
+			fmt.Fprintf(&sb, "%s:\n", label)
+		}
+	}
+
+	return sb.String()
+}
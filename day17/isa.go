@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/too-gee/advent-of-code-2024/shared/input"
+)
+
+// MaxRegisters is how many registers a State has room for. Keeping them in an
+// array rather than a slice means copying a State copies its registers.
+const MaxRegisters = 8
+
+const (
+	registerA = iota
+	registerB
+	registerC
+)
+
+// Opcode describes one instruction: its mnemonic, how its operand is read,
+// pseudocode for the disassembler with {} standing in for the operand, and
+// the handler that runs it
+type Opcode struct {
+	Name    string
+	Operand operandKind
+	Effect  string
+	Run     func(s *State, operand int)
+}
+
+// InstructionSet defines a machine. Register i is addressed by combo operand
+// 4+i. The format hooks are optional, and default to the puzzle's formats.
+type InstructionSet struct {
+	Registers []string
+	Opcodes   []Opcode
+
+	// OperandBits is how wide an operand is, 0 for the puzzle's 3 bits
+	OperandBits int
+
+	// ReadInput parses the initial registers and the program
+	ReadInput func(isa *InstructionSet, r io.Reader) ([]int64, []int, error)
+	// ParseProgram parses the text after "Program: "
+	ParseProgram func(text string) ([]int, error)
+	// FormatOutput renders what the program printed
+	FormatOutput func(output []int) string
+}
+
+// Chronospatial is the 3-bit computer from the puzzle
+var Chronospatial = &InstructionSet{Registers: []string{"A", "B", "C"}}
+
+func init() {
+	// the handlers look up the instruction set of the state they run on, so
+	// the table can't be part of the variable's initializer
+	Chronospatial.Opcodes = []Opcode{
+		{"adv", comboOperand, "A = A >> {}", (*State).adv},
+		{"bxl", literalOperand, "B = B ^ {}", (*State).bxl},
+		{"bst", comboOperand, "B = {} % 8", (*State).bst},
+		{"jnz", jumpOperand, "if A != 0 goto {}", (*State).jnz},
+		{"bxc", ignoredOperand, "B = B ^ C", (*State).bxc},
+		{"out", comboOperand, "out({} % 8)", (*State).out},
+		{"bdv", comboOperand, "B = A >> {}", (*State).bdv},
+		{"cdv", comboOperand, "C = A >> {}", (*State).cdv},
+	}
+}
+
+// ErrUnsupportedISA is returned by the tools that only understand the puzzle's
+// opcodes
+var ErrUnsupportedISA = errors.New("only the Chronospatial instruction set is supported")
+
+// NewInstructionSet builds an instruction set, rejecting one with more
+// registers than a State can hold. Operands are 3 bits, or wider if that's
+// what it takes for a combo operand to name every register.
+func NewInstructionSet(registers []string, opcodes []Opcode) (*InstructionSet, error) {
+	isa := &InstructionSet{Registers: registers, Opcodes: opcodes, OperandBits: max(3, bits.Len(uint(len(registers)+3)))}
+	if err := isa.Validate(); err != nil {
+		return nil, err
+	}
+
+	return isa, nil
+}
+
+// Validate checks the instruction set fits in a State, and that every
+// register can be named by a combo operand
+func (isa *InstructionSet) Validate() error {
+	if len(isa.Registers) > MaxRegisters {
+		return fmt.Errorf("instruction set has %d registers, a State only has room for %d", len(isa.Registers), MaxRegisters)
+	}
+
+	if isa.OperandBits < 0 || isa.OperandBits > 16 {
+		return fmt.Errorf("operands can't be %d bits wide", isa.OperandBits)
+	}
+
+	if last := 4 + len(isa.Registers) - 1; last >= isa.operandLimit() {
+		return fmt.Errorf("register %s needs combo operand %d, past the %d-bit operand range", isa.Registers[len(isa.Registers)-1], last, isa.operandBits())
+	}
+
+	return nil
+}
+
+// operandBits is how wide an operand is
+func (isa *InstructionSet) operandBits() int {
+	if isa.OperandBits == 0 {
+		return 3
+	}
+
+	return isa.OperandBits
+}
+
+// operandLimit is one more than the largest operand, and so also the first
+// address a jump can't reach
+func (isa *InstructionSet) operandLimit() int {
+	return 1 << isa.operandBits()
+}
+
+// NewState sets up a program to run on the instruction set. The registers are
+// given in the instruction set's order, and any left out start at zero.
+func (isa *InstructionSet) NewState(registers []int64, program []int) (State, error) {
+	if err := isa.Validate(); err != nil {
+		return State{}, err
+	}
+
+	if len(registers) > len(isa.Registers) {
+		return State{}, fmt.Errorf("got %d register values for %d registers", len(registers), len(isa.Registers))
+	}
+
+	state := State{ISA: isa, Program: program, Output: []int{}}
+	copy(state.Registers[:], registers)
+
+	return state, nil
+}
+
+// Opcode looks up an opcode by number
+func (isa *InstructionSet) Opcode(code int) (Opcode, bool) {
+	if code < 0 || code >= len(isa.Opcodes) || isa.Opcodes[code].Run == nil {
+		return Opcode{}, false
+	}
+
+	return isa.Opcodes[code], true
+}
+
+// Lookup finds an opcode by its mnemonic, ignoring case
+func (isa *InstructionSet) Lookup(name string) (int, bool) {
+	code := slices.IndexFunc(isa.Opcodes, func(o Opcode) bool { return o.Run != nil && strings.EqualFold(o.Name, name) })
+
+	return code, code >= 0
+}
+
+// Register finds a register by name, ignoring case
+func (isa *InstructionSet) Register(name string) (int, bool) {
+	register := slices.IndexFunc(isa.Registers, func(r string) bool { return strings.EqualFold(r, name) })
+
+	return register, register >= 0
+}
+
+// ComboName renders a combo operand as a literal or a register name
+func (isa *InstructionSet) ComboName(operand int) (string, bool) {
+	switch {
+	case operand >= 0 && operand < 4:
+		return strconv.Itoa(operand), true
+	case operand >= 4 && operand-4 < len(isa.Registers):
+		return isa.Registers[operand-4], true
+	}
+
+	return "", false
+}
+
+// ComboOperand is the inverse of ComboName
+func (isa *InstructionSet) ComboOperand(text string) (int, bool) {
+	if register, ok := isa.Register(text); ok {
+		return register + 4, true
+	}
+
+	operand, err := strconv.Atoi(text)
+	if err != nil || operand < 0 || operand >= 4 {
+		return 0, false
+	}
+
+	return operand, true
+}
+
+var registerLine = regexp.MustCompile(`^Register (?P<Name>\w+): (?P<Value>-?\d+)$`)
+var programLine = regexp.MustCompile(`^Program: (?P<Text>.*)$`)
+
+// Read parses puzzle input with the instruction set's input format. The
+// default format is one "Register A: 729" line per register, in any order,
+// followed by a "Program: " line.
+func (isa *InstructionSet) Read(r io.Reader) ([]int64, []int, error) {
+	if isa.ReadInput != nil {
+		return isa.ReadInput(isa, r)
+	}
+
+	lines, err := input.Lines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registers := make([]int64, len(isa.Registers))
+	var program []int
+
+	for i, line := range lines {
+		var register struct {
+			Name  string
+			Value int64
+		}
+		var code struct{ Text string }
+
+		switch {
+		case line == "":
+			continue
+		case input.DecodeLine(line, registerLine, &register) == nil:
+			index, ok := isa.Register(register.Name)
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown register %q", i+1, register.Name)
+			}
+			registers[index] = register.Value
+		case input.DecodeLine(line, programLine, &code) == nil:
+			if program, err = isa.Parse(code.Text); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		default:
+			return nil, nil, fmt.Errorf("line %d: unexpected %q", i+1, line)
+		}
+	}
+
+	if program == nil {
+		return nil, nil, fmt.Errorf("no program found")
+	}
+
+	return registers, program, nil
+}
+
+// Parse reads a program with the instruction set's program format. The
+// default format is comma separated numbers.
+func (isa *InstructionSet) Parse(text string) ([]int, error) {
+	if isa.ParseProgram != nil {
+		return isa.ParseProgram(text)
+	}
+
+	program := []int{}
+	for _, field := range strings.Split(text, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid program value %q", field)
+		}
+		program = append(program, value)
+	}
+
+	return program, nil
+}
+
+// Format renders output with the instruction set's output format. The
+// default format is comma separated numbers.
+func (isa *InstructionSet) Format(output []int) string {
+	if isa.FormatOutput != nil {
+		return isa.FormatOutput(output)
+	}
+
+	return strings.Join(IntsToStrings(output), ",")
+}
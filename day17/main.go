@@ -1,153 +1,184 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
-	"math"
+	"slices"
 )
 
 func main() {
+	disasm := flag.Bool("disasm", false, "print an annotated listing of the program and exit")
+	decompile := flag.Bool("decompile", false, "print the program as pseudocode and exit")
+	trace := flag.Bool("trace", false, "print the machine state after every instruction and exit")
+	debug := flag.Bool("debug", false, "step through the program interactively")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
 
 	registers, program := readInput(fileName)
 
+	if *disasm {
+		fmt.Print(Disassemble(program))
+		return
+	}
+
+	if *decompile {
+		decompiled, err := Decompile(program)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Print(decompiled)
+		return
+	}
+
+	state, err := NewState(registers, program)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if *trace {
+		entries, halted := state.Trace(StepLimit)
+		for _, entry := range entries {
+			fmt.Println(entry)
+		}
+		if !halted {
+			fmt.Printf("trace truncated after %d steps, the program may never halt\n", len(entries))
+		}
+		return
+	}
+
+	if *debug {
+		RunDebugger(NewDebugger(state), os.Stdin, os.Stdout)
+		return
+	}
+
 	// Part 1
 	registers, output := Part1(registers, program)
 	fmt.Printf("Part 1: registers - %v, output - %s\n", registers, output)
 
 	// Part 2
-	registers, output = Part2(registers, program)
+	registers, output, err = Part2(registers, program)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Printf("Part 2: registers - %v, Initial Register A - %s\n", registers, output)
 }
 
 func readInput(filePath string) ([]int64, []int) {
-	file, _ := os.Open(filePath)
+	file, err := os.Open(filePath)
+	if err != nil {
+		fmt.Printf("Error opening %s", filePath)
+		return nil, nil
+	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	var a, b, c int64
-	var program []int
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		fmt.Sscanf(line, "Register A: %d", &a)
-		fmt.Sscanf(line, "Register B: %d", &b)
-		fmt.Sscanf(line, "Register C: %d", &c)
-
-		var tmpProgram string
-		_, err := fmt.Sscanf(line, "Program: %s", &tmpProgram)
-
-		if err == nil {
-			tmpProgram := strings.Split(tmpProgram, ",")
-			for _, tmpStr := range tmpProgram {
-				tmpInt, _ := strconv.Atoi(tmpStr)
-				program = append(program, tmpInt)
-			}
-		}
+	registers, program, err := Chronospatial.Read(file)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil, nil
 	}
 
-	return []int64{a, b, c}, program
+	return registers, program
 }
 
 type State struct {
+	// ISA is the machine the program runs on, nil for the puzzle's
+	ISA *InstructionSet
+
 	Program []int
 	Pointer int
 
-	RegisterA int64
-	RegisterB int64
-	RegisterC int64
+	Registers [MaxRegisters]int64
 
 	Output []int
 }
 
-func (s *State) Execute() {
-	for (*s).Pointer < len((*s).Program) {
-		opcode := (*s).Program[(*s).Pointer]
-		operand := (*s).Program[(*s).Pointer+1]
-
-		switch opcode {
-			case 0:
-				(*s).adv(operand)
-			case 1:
-				(*s).bxl(operand)
-			case 2:
-				(*s).bst(operand)
-			case 3:
-				(*s).jnz(operand)
-			case 4:
-				(*s).bxc(operand)
-			case 5:
-				(*s).out(operand)
-			case 6:
-				(*s).bdv(operand)
-			case 7:
-				(*s).cdv(operand)
-		}
+// NewState sets up a program to run on the puzzle's machine
+func NewState(registers []int64, program []int) (State, error) {
+	return Chronospatial.NewState(registers, program)
+}
+
+func (s State) instructionSet() *InstructionSet {
+	if s.ISA == nil {
+		return Chronospatial
 	}
+
+	return s.ISA
 }
 
-func (s State) RenderOutput() string {
-	return strings.Join(IntsToStrings(s.Output), ",")
+// RegisterValues returns the registers the instruction set uses
+func (s State) RegisterValues() []int64 {
+	return slices.Clone(s.Registers[:min(len(s.instructionSet().Registers), MaxRegisters)])
 }
 
-func (s State) DebugOutput(a int64, msg string, compare []int) []int {
-	s.RegisterA = a
-	s.Execute()
+func (s *State) Execute() {
+	for (*s).Step() {
+	}
+}
 
-	aOctal := strconv.FormatInt(int64(a), 8)
+// Step runs the instruction at the pointer. It returns false without doing
+// anything once the program has halted.
+func (s *State) Step() bool {
+	if (*s).Halted() {
+		return false
+	}
 
-	display := "["
-	for i, digit := range s.Output {
-		if len(compare) == len(s.Output) && digit != compare[i] {
-			display += fmt.Sprintf("«%s»", strconv.Itoa(digit))
-		} else {
-			display += fmt.Sprintf(" %s ", strconv.Itoa(digit))
-		}
+	code := (*s).Program[(*s).Pointer]
+	operand := (*s).Program[(*s).Pointer+1]
+
+	opcode, ok := (*s).instructionSet().Opcode(code)
+	if !ok {
+		panic(fmt.Sprintf("Invalid opcode %d at %d!", code, (*s).Pointer))
 	}
-	display += "]"
 
-	fmt.Printf("%-15s @ %15d / %19s: %v\n", msg, a, fmt.Sprintf("0o%s" ,aOctal), display)
+	opcode.Run(s, operand)
+
+	return true
+}
 
-	return s.Output
+// Halted reports whether the pointer has run off the end of the program
+func (s State) Halted() bool {
+	return s.Pointer < 0 || s.Pointer+1 >= len(s.Program)
+}
+
+func (s State) RenderOutput() string {
+	return s.instructionSet().Format(s.Output)
 }
 
 // OPCODE 0
 func (s *State) adv(comboOperand int) {
 	operand := (*s).ResolveComboOperand(comboOperand)
-	numerator := float64((*s).RegisterA)
-	denominator := math.Pow(2, float64(operand))
-
-	(*s).RegisterA = int64(math.Trunc(numerator / denominator))
+	(*s).Registers[registerA] = divPow2((*s).Registers[registerA], operand)
 	(*s).Pointer += 2
 }
 
 // OPCODE 1
 func (s *State) bxl(operand int) {
-	(*s).RegisterB ^= int64(operand)
+	(*s).Registers[registerB] ^= int64(operand)
 	(*s).Pointer += 2
 }
 
 // OPCODE 2
 func (s *State) bst(comboOperand int) {
 	operand := (*s).ResolveComboOperand(comboOperand)
-	(*s).RegisterB = int64(operand % 8)
+	(*s).Registers[registerB] = int64(operand % 8)
 	(*s).Pointer += 2
 }
 
 // OPCODE 3
 func (s *State) jnz(operand int) {
-	if (*s).RegisterA == 0 {
+	if (*s).Registers[registerA] == 0 {
 		(*s).Pointer += 2
 		return
 	}
@@ -157,7 +188,7 @@ func (s *State) jnz(operand int) {
 
 // OPCODE 4
 func (s *State) bxc(operand int) {
-	(*s).RegisterB ^= (*s).RegisterC
+	(*s).Registers[registerB] ^= (*s).Registers[registerC]
 	(*s).Pointer += 2
 }
 
@@ -172,164 +203,62 @@ func (s *State) out(comboOperand int) {
 // OPCODE 6
 func (s *State) bdv(comboOperand int) {
 	operand := (*s).ResolveComboOperand(comboOperand)
-	numerator := float64((*s).RegisterA)
-	denominator := math.Pow(2, float64(operand))
-
-	(*s).RegisterB = int64(math.Trunc(numerator / denominator))
+	(*s).Registers[registerB] = divPow2((*s).Registers[registerA], operand)
 	(*s).Pointer += 2
 }
 
 // OPCODE 7
 func (s *State) cdv(comboOperand int) {
 	operand := (*s).ResolveComboOperand(comboOperand)
-	numerator := float64((*s).RegisterA)
-	denominator := math.Pow(2, float64(operand))
-
-	(*s).RegisterC = int64(math.Trunc(numerator / denominator))
+	(*s).Registers[registerC] = divPow2((*s).Registers[registerA], operand)
 	(*s).Pointer += 2
 }
 
-func Part2(registers []int64, program []int) ([]int64, string) {
-	floor := RegisterAForLength(len(program), registers, program)
-	ceiling := RegisterAForLength(len(program)+1, registers, program)-1
-
-	state := State{
-		Program: program,
-		Pointer: 0,
-		RegisterA: registers[0],
-		RegisterB: registers[1],
-		RegisterC: registers[2],
-		Output: []int{},
+// divPow2 divides by 2^power, truncating toward zero. Going through float64
+// would lose precision once values pass 2^53.
+func divPow2(value int64, power int64) int64 {
+	switch {
+	case power >= 63:
+		return 0
+	case power < 0:
+		return value << min(-power, 63)
 	}
 
-	state.DebugOutput(floor - 1, "low-miss", nil)
-	state.DebugOutput(floor, "floor", nil)
-	state.DebugOutput(ceiling, "ceiling", nil)
-	state.DebugOutput(ceiling + 1, "high-miss", nil)
-
-	fmt.Println("--------------")
-
-	registerA := FindMatch(state, floor)
-
-	return []int64{0,0,0}, strconv.FormatInt(registerA, 10)
+	return value / (1 << power)
 }
 
-/*
-	Once you realize that the program output acts like an odometer (hopefully, mine did)
-	and increases sequentially, you can jump ahead to find when each digit rolls over.
-	This was easier for me than reverse engineering the program. In order to run in a
-	reasonable amount of time, this had to be tuned a bunch. I ended up with the numbers
-	here after a bunch of trial and error to reduce the number of iterations. If the
-	parameters are wrong, the process will overshoot on fast iterations and waste time
-	catching up on slow iterations. This approach runs several hundred million times
-	faster than a fully naive approach. On my machine, that's the difference between
-	~10 seconds and ~40 years.
-*/
-func FindMatch(state State, start int64) int64 {
-	places := octalPlaces(start)
-
-	bookmark := start
-	registerA := start
-
-	var output []int
-	var inc int64
-
-	for i := places-1; i >= 0; i-- {
-		inc = int64(math.Pow(8, float64(i)))
-
-		for {
-			registerA += inc
-
-			output = state.DebugOutput(registerA, fmt.Sprintf("digit: %d, speed: %d", i, inc), state.Program)
-
-			match := Compare(output[i:], state.Program[i:])
-			tooBig := octalPlaces(registerA) > places
-
-			if tooBig || match {
-				if tooBig {
-					registerA = bookmark
-				}
-
-				if match {
-					bookmark = registerA
-					registerA -= inc
-				}
-
-				if inc == 1 {
-					inc = 0
-					continue
-				} else {
-					inc /= 8
-				}
-			}
-
-			if inc == 0 { break }
-		}
-
-		registerA = bookmark
-
-		if Compare(output, state.Program) { break }
+// Part2 finds the smallest A that makes the program output itself. Only
+// programs SolveQuine can work backward through are supported; anything else
+// returns its ErrNotShiftLoop.
+func Part2(registers []int64, program []int) ([]int64, string, error) {
+	registerA, err := SolveQuine(registers, program)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return registerA
-}
-
-func RegisterAForLength(length int, registers []int64, program []int) int64 {
-	var registerA int64
-	registerA = 0
-
-	inc := int64(math.Pow(8, 20))
-
-	state := State{
-		Program: program,
-		Pointer: 0,
-		RegisterA: registers[0],
-		RegisterB: registers[1],
-		RegisterC: registers[2],
-		Output: []int{},
-	}
-
-	for ; inc >= 1; registerA += inc {
-		output := state.DebugOutput(registerA, "finding length", nil)
-
-		if len(output) >= length {
-			registerA = int64(math.Max(float64(registerA-inc),0))
-			inc /= 8
-		}
-	}
-	fmt.Println(registerA + 1)
-	return registerA + 1
+	return []int64{0,0,0}, strconv.FormatInt(registerA, 10), nil
 }
 
 func Part1(registers []int64, program []int) ([]int64, string) {
-	state := State{
-		Program: program,
-		Pointer: 0,
-
-		RegisterA: registers[0],
-		RegisterB: registers[1],
-		RegisterC: registers[2],
-
-		Output: []int{},
+	state, err := NewState(registers, program)
+	if err != nil {
+		fmt.Println(err)
+		return nil, ""
 	}
 
 	state.Execute()
 
-	return []int64{state.RegisterA, state.RegisterB, state.RegisterC}, state.RenderOutput()
+	return state.RegisterValues(), state.RenderOutput()
 }
 
 func (s *State) ResolveComboOperand(operand int) int64 {
-	switch operand {
-	case 4:
-		return s.RegisterA
-	case 5:
-		return s.RegisterB
-	case 6:
-		return s.RegisterC
-	case 7:
-		panic("Invalid operand value!")
-	default:
+	switch {
+	case operand < 4:
 		return int64(operand)
+	case operand-4 < len(s.instructionSet().Registers):
+		return s.Registers[operand-4]
+	default:
+		panic("Invalid operand value!")
 	}
 }
 
@@ -361,12 +290,4 @@ func Compare(a, b []int) bool {
 		}
 	}
 	return true
-}
-
-func octalPlaces(num int64) int {
-    if num == 0 {
-        return 1
-    }
-
-    return int(math.Floor(math.Log(float64(num)) / math.Log(8))) + 1
 }
\ No newline at end of file
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrNotShiftLoop = errors.New("program is not a single loop that shifts A by 3")
+var ErrNoQuine = errors.New("no value of A makes the program output itself")
+
+// CheckShiftLoop reports whether the program has the shape the backward search
+// relies on: one loop over the whole program, ending in "jnz 0", where each
+// pass shifts A right by exactly 3 and outputs one value that depends only on
+// A. Anything B and C carry over from the previous pass must not matter.
+func CheckShiftLoop(program []int) error {
+	decompiled, err := Decompile(program)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotShiftLoop, err)
+	}
+
+	if !decompiled.Loop {
+		return fmt.Errorf("%w: program is not one loop ending in jnz 0", ErrNotShiftLoop)
+	}
+
+	if decompiled.Shift() != 3 {
+		return fmt.Errorf("%w: each pass sets A = %s", ErrNotShiftLoop, decompiled.Registers["A"])
+	}
+
+	if len(decompiled.Outputs) != 1 {
+		return fmt.Errorf("%w: found %d outputs per pass", ErrNotShiftLoop, len(decompiled.Outputs))
+	}
+
+	output := decompiled.Outputs[0]
+	if slices.ContainsFunc(FreeRegisters(output), func(r string) bool { return r != "A" }) {
+		return fmt.Errorf("%w: output %s depends on more than A", ErrNotShiftLoop, output)
+	}
+
+	return nil
+}
+
+// SolveQuine finds the smallest A that makes the program output itself. Each
+// pass of the loop outputs one value and drops the lowest 3 bits of A, so the
+// last output depends only on the top octal digit of A. Working backward from
+// the last output, each step tries the 8 possible next digits and keeps those
+// that reproduce the tail of the program, backtracking on dead ends.
+func SolveQuine(registers []int64, program []int) (int64, error) {
+	if len(registers) < 3 {
+		return 0, fmt.Errorf("expected registers A, B and C, got %d registers", len(registers))
+	}
+
+	if err := CheckShiftLoop(program); err != nil {
+		return 0, err
+	}
+
+	compiled, err := Compile(program)
+	if err != nil {
+		return 0, err
+	}
+
+	var search func(a int64, i int) (int64, bool)
+	search = func(a int64, i int) (int64, bool) {
+		if i < 0 {
+			return a, a != 0
+		}
+
+		for digit := range int64(8) {
+			candidate := a<<3 | digit
+
+			if !compiled.Matches(candidate, registers[1], registers[2], program[i:]) {
+				continue
+			}
+
+			if result, ok := search(candidate, i-1); ok {
+				return result, true
+			}
+		}
+
+		return 0, false
+	}
+
+	result, ok := search(0, len(program)-1)
+	if !ok {
+		return 0, ErrNoQuine
+	}
+
+	return result, nil
+}
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
+	"image/gif"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
@@ -29,3 +34,171 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeline(t *testing.T) {
+	cases := []struct {
+		fileName      string
+		initialBlocks int
+		size          int
+		length        int
+		blockedAt     int
+	}{
+		{"input_small.txt", 12, 6, 22, 20},
+		{"input.txt", 1024, 70, 260, 2881},
+	}
+
+	for _, c := range cases {
+		blocks := readInput(c.fileName)
+		timeline := NewTimeline(blocks, c.size)
+
+		if len(timeline.Moments) != len(blocks) {
+			t.Fatalf("%s: expected %d moments, got %d", c.fileName, len(blocks), len(timeline.Moments))
+		}
+
+		if length := timeline.Moments[c.initialBlocks-1].Length; length != c.length {
+			t.Errorf("%s: expected length %d after %d bytes, got %d", c.fileName, c.length, c.initialBlocks, length)
+		}
+
+		if blockedAt := timeline.FirstBlocked(); blockedAt != c.blockedAt {
+			t.Errorf("%s: expected byte %d to block the exit, got %d", c.fileName, c.blockedAt, blockedAt)
+		}
+
+		frames := 0
+		for i, m := range timeline.Moments {
+			if i == 0 || m.Rerouted {
+				frames++
+			}
+			if i == 0 {
+				continue
+			}
+
+			previous := timeline.Moments[i-1]
+			if m.Rerouted != (previous.Reachable && slices.Contains(previous.Path, m.Block)) {
+				t.Errorf("%s: byte %d at %v: rerouted is %v", c.fileName, i, m.Block, m.Rerouted)
+			}
+			if m.Reachable && m.Length < previous.Length {
+				t.Errorf("%s: byte %d made the path shorter", c.fileName, i)
+			}
+		}
+
+		var table bytes.Buffer
+		if err := timeline.WriteCSV(&table); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(&table).ReadAll()
+		if err != nil || len(rows) != len(blocks)+1 {
+			t.Errorf("%s: expected %d CSV rows, got %d (%v)", c.fileName, len(blocks)+1, len(rows), err)
+		}
+
+		var animation bytes.Buffer
+		if err := timeline.WriteGIF(&animation, 2); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := gif.DecodeAll(&animation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded.Image) != frames {
+			t.Errorf("%s: expected %d frames, got %d", c.fileName, frames, len(decoded.Image))
+		}
+	}
+}
+
+func TestTimelineRepeatable(t *testing.T) {
+	// an open map is full of ties, so every run has to break them the same way
+	blocks := readInput("input.txt")
+
+	export := func() (string, string) {
+		timeline := NewTimeline(blocks, 70)
+
+		var table, animation bytes.Buffer
+		if err := timeline.WriteCSV(&table); err != nil {
+			t.Fatal(err)
+		}
+		if err := timeline.WriteGIF(&animation, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		return table.String(), animation.String()
+	}
+
+	table, animation := export()
+	for run := 2; run <= 3; run++ {
+		againTable, againAnimation := export()
+		if againTable != table {
+			t.Errorf("run %d: CSV differs from the first run", run)
+		}
+		if againAnimation != animation {
+			t.Errorf("run %d: GIF differs from the first run", run)
+		}
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	// the same wall, with the path having to come back down the other side
+	grid := shared.MakeGrid(30, 30)
+	for y := range 29 {
+		grid[y][20] = "#"
+	}
+	start, end := shared.Coord{X: 15, Y: 0}, shared.Coord{X: 25, Y: 0}
+
+	path := ShortestPath(grid, start, end)
+	if len(path) != 29+29+10+1 || path[0] != start || path[len(path)-1] != end {
+		t.Fatalf("expected %d tiles from %v to %v, got %v", 29+29+10+1, start, end, path)
+	}
+	for i := 1; i < len(path); i++ {
+		if !slices.Contains(slices.Collect(maps.Values(path[i-1].Neighbors())), path[i]) || grid.At(path[i]) == "#" {
+			t.Errorf("step %d from %v to %v is not a move", i, path[i-1], path[i])
+		}
+	}
+
+	grid[29][20] = "#"
+	if path := ShortestPath(grid, start, end); path != nil {
+		t.Errorf("expected no path through the wall, got %v", path)
+	}
+}
+
+func TestGetCosts(t *testing.T) {
+	// open grids are where the old lives heuristic ran out of lives
+	for _, size := range [][2]int{{1, 1}, {7, 7}, {40, 40}, {90, 25}} {
+		width, height := size[0], size[1]
+		grid := shared.MakeGrid(width, height)
+		costs := GetCosts(grid, shared.Coord{X: 0, Y: 0})
+
+		if len(costs) != width*height {
+			t.Errorf("%dx%d: expected %d reachable cells, got %d", width, height, width*height, len(costs))
+		}
+
+		for y := range height {
+			for x := range width {
+				if cost := costs[shared.Coord{X: x, Y: y}]; cost != x+y {
+					t.Errorf("%dx%d: expected %d steps to %d,%d, got %d", width, height, x+y, x, y, cost)
+				}
+			}
+		}
+	}
+
+	// from the middle of an open grid, with a wall to walk around
+	grid := shared.MakeGrid(30, 30)
+	for y := range 29 {
+		grid[y][20] = "#"
+	}
+	middle := shared.Coord{X: 15, Y: 15}
+	costs := GetCosts(grid, middle)
+
+	if len(costs) != 30*30-29 {
+		t.Errorf("walled: expected %d reachable cells, got %d", 30*30-29, len(costs))
+	}
+	if cost := costs[shared.Coord{X: 21, Y: 0}]; cost != 5+14+1+29 {
+		t.Errorf("walled: expected %d steps around the wall, got %d", 5+14+1+29, cost)
+	}
+	if _, ok := costs[shared.Coord{X: 20, Y: 0}]; ok {
+		t.Errorf("walled: walls shouldn't have a cost")
+	}
+
+	// cells cut off from the start are left out
+	grid = createGrid([]shared.Coord{{X: 1, Y: 0}, {X: 0, Y: 1}}, 5)
+	if costs := GetCosts(grid, shared.Coord{X: 0, Y: 0}); len(costs) != 1 || costs[shared.Coord{X: 0, Y: 0}] != 0 {
+		t.Errorf("boxed in: expected only the start, got %v", costs)
+	}
+}
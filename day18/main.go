@@ -2,9 +2,8 @@ package main
 
 import (
 	"bufio"
-	"container/heap"
+	"flag"
 	"fmt"
-	"math"
 	"os"
 	"slices"
 	"strings"
@@ -13,10 +12,14 @@ import (
 )
 
 func main() {
+	timelineCSV := flag.Bool("csv", false, "print the path length after every byte as CSV and exit")
+	timelineGIF := flag.String("gif", "", "write an animation of the path rerouting to this file and exit")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
@@ -33,6 +36,27 @@ func main() {
 		initialBlocks = 12
 	}
 
+	if *timelineCSV {
+		if err := NewTimeline(blocks, size).WriteCSV(os.Stdout); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	if *timelineGIF != "" {
+		file, err := os.Create(*timelineGIF)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer file.Close()
+
+		if err := NewTimeline(blocks, size).WriteGIF(file, 8); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
 	// Part 1
 	bestCost := Part1(blocks, initialBlocks, size)
 	fmt.Printf("The shortest path is %d steps\n", bestCost)
@@ -61,34 +85,19 @@ func readInput(filePath string) []shared.Coord {
 	return blocks
 }
 
+// Part1 is the fewest steps to the exit after the initial blocks fall, or -1
+// if they've already cut it off
 func Part1(blocks []shared.Coord, initialBlocks int, size int) int {
 	grid := createGrid(blocks[:initialBlocks], size+1)
 	start := shared.Coord{X: 0, Y: 0}
 	end := shared.Coord{X: size, Y: size}
-	// grid.Draw(nil, map[string][]shared.Coord{"🟢": {start}, "🔴": {end}})
 
-	forwardCosts := GetCosts(grid, end)
-	reverseCosts := GetCosts(grid, start)
-
-	bestCost := math.MaxInt
-	for y := range grid.Height() {
-		for x := range grid.Width() {
-			loc := shared.Coord{X: x, Y: y}
-
-			forwardCost, fok := forwardCosts[loc]
-			reverseCost, rok := reverseCosts[loc]
-
-			if fok && rok {
-				if bestCost > forwardCost+reverseCost {
-					bestCost = forwardCost + reverseCost
-				}
-			}
-		}
+	cost, ok := GetCosts(grid, start)[end]
+	if !ok {
+		return -1
 	}
 
-	// subtract 2 since the first step in the first path is the start
-	// and the first step in the second path is included in the first path
-	return bestCost - 2
+	return cost
 }
 
 func Part2(blocks []shared.Coord, initialBlocks int, size int) int {
@@ -182,125 +191,7 @@ func (q *DumbQueue) pop() shared.Coord {
 	return item
 }
 
-type State struct {
-	path  []shared.Coord
-	lives int
-}
-
-type Queue []*State
-
-func (q Queue) Len() int { return len(q) }
-
-func (q Queue) Less(i, j int) bool {
-	return len(q[i].path) < len(q[j].path)
-}
-
-func (q Queue) Swap(i, j int) {
-	q[i], q[j] = q[j], q[i]
-}
-
-func (q *Queue) Push(x interface{}) {
-	item := x.(*State)
-	*q = append(*q, item)
-}
-
-func (q *Queue) Pop() interface{} {
-	old := *q
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	*q = old[0 : n-1]
-	return item
-}
-
-// NewPriorityQueue creates and initializes a new priority queue
-func NewPriorityQueue() *Queue {
-	q := &Queue{}
-	heap.Init(q)
-	return q
-}
-
-// PushState adds a new State to the priority queue
-func (q *Queue) PushState(state *State) {
-	heap.Push(q, state)
-}
-
-// PopState removes and returns the State with the shortest path
-func (q *Queue) PopState() *State {
-	return heap.Pop(q).(*State)
-}
-
-func CopyAppend(a []shared.Coord, b shared.Coord) []shared.Coord {
-	newSlice := make([]shared.Coord, len(a)+1)
-	copy(newSlice, a)
-	newSlice[len(a)] = b
-
-	return newSlice
-}
-
+// GetCosts is the number of steps from start to every cell it can reach
 func GetCosts(m shared.Grid, start shared.Coord) map[shared.Coord]int {
-	lives := 10
-	queue := Queue{}
-	queue.PushState(&State{path: []shared.Coord{start}, lives: lives})
-
-	visitedCost := map[shared.Coord]int{}
-
-	// for debug
-	counter := 0
-
-	for len(queue) > 0 {
-		current := queue.PopState()
-
-		if current.lives == 0 {
-			continue
-		}
-
-		length := len(current.path)
-		loc := current.path[length-1]
-
-		// for debug
-		counter++
-		if counter%10000000 == 0 {
-			m.Draw(nil, map[string][]shared.Coord{"🟢": {start}, "🟨": current.path[1:]})
-		}
-
-		// fill a cost grid
-		localShortest, ok := visitedCost[loc]
-
-		if !ok || length < localShortest {
-			visitedCost[loc] = length
-			localShortest = length
-		}
-
-		// unless this is an improvement, skip
-		if length > localShortest {
-			continue
-		}
-
-		neighbors := m.Neighbors(loc, []string{"#"})
-
-		obstacles := 4 - len(neighbors)
-		if loc.X == 0 || loc.X == m.Width()-1 {
-			obstacles--
-		}
-		if loc.Y == 0 || loc.Y == m.Height()-1 {
-			obstacles--
-		}
-
-		if obstacles == 0 {
-			current.lives--
-		} else {
-			current.lives = int(math.Min(float64(lives), float64(current.lives+1)))
-		}
-
-		for _, neighbor := range neighbors {
-			if slices.Contains(current.path, neighbor) {
-				continue
-			}
-
-			queue.PushState(&State{path: CopyAppend(current.path, neighbor), lives: current.lives})
-		}
-	}
-
-	return visitedCost
+	return m.Distances(start, []string{"#"})
 }
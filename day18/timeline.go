@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+// Moment is the memory space just after one more byte has fallen
+type Moment struct {
+	Byte      int
+	Block     shared.Coord
+	Reachable bool
+
+	// Length is the number of steps in the shortest path, or -1 once the exit
+	// can't be reached
+	Length int
+	Path   []shared.Coord
+
+	// Rerouted is set when the byte landed on the previous path, so the path
+	// had to be found again
+	Rerouted bool
+}
+
+// Timeline follows the shortest path from the top left corner to the exit as
+// the bytes fall, one Moment per byte
+type Timeline struct {
+	Size    int
+	Moments []Moment
+}
+
+// NewTimeline drops every byte in turn. A byte that misses the current path
+// can't make it any longer, so the path is only searched for again when a
+// byte lands on it.
+func NewTimeline(blocks []shared.Coord, size int) Timeline {
+	grid := createGrid(nil, size+1)
+	start := shared.Coord{X: 0, Y: 0}
+	end := shared.Coord{X: size, Y: size}
+
+	path := ShortestPath(grid, start, end)
+	onPath := pathSet(path)
+
+	timeline := Timeline{Size: size}
+
+	for i, block := range blocks {
+		grid[block.Y][block.X] = "#"
+		rerouted := false
+
+		if path != nil && onPath[block] {
+			path = ShortestPath(grid, start, end)
+			onPath = pathSet(path)
+			rerouted = true
+		}
+
+		timeline.Moments = append(timeline.Moments, Moment{
+			Byte:      i,
+			Block:     block,
+			Reachable: path != nil,
+			Length:    len(path) - 1,
+			Path:      path,
+			Rerouted:  rerouted,
+		})
+	}
+
+	return timeline
+}
+
+// FirstBlocked is the index of the byte that cuts off the exit, or -1
+func (t Timeline) FirstBlocked() int {
+	for _, moment := range t.Moments {
+		if !moment.Reachable {
+			return moment.Byte
+		}
+	}
+
+	return -1
+}
+
+func pathSet(path []shared.Coord) map[shared.Coord]bool {
+	set := make(map[shared.Coord]bool, len(path))
+	for _, loc := range path {
+		set[loc] = true
+	}
+
+	return set
+}
+
+// pathSteps is the order ShortestPath tries its neighbours in: north, east,
+// south, west. Grid.Neighbors is a map, so ties would go a different way on
+// every run.
+var pathSteps = []shared.Coord{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+
+// ShortestPath finds a shortest path from start to end, both included, by
+// walking back from end through cells one step closer to start. It returns nil
+// when end can't be reached.
+func ShortestPath(g shared.Grid, start shared.Coord, end shared.Coord) []shared.Coord {
+	distances := g.Distances(start, []string{"#"})
+
+	distance, ok := distances[end]
+	if !ok {
+		return nil
+	}
+
+	path := []shared.Coord{end}
+	for current := end; distance > 0; distance-- {
+		for _, step := range pathSteps {
+			neighbor := shared.Coord{X: current.X + step.X, Y: current.Y + step.Y}
+			if d, ok := distances[neighbor]; ok && d == distance-1 {
+				current = neighbor
+				break
+			}
+		}
+		path = append(path, current)
+	}
+
+	slices.Reverse(path)
+
+	return path
+}
+
+// WriteCSV writes one row per byte: its index and position, whether the exit
+// can still be reached, the shortest path length and whether the path moved
+func (t Timeline) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"byte", "x", "y", "reachable", "length", "rerouted"})
+
+	for _, m := range t.Moments {
+		out.Write([]string{
+			strconv.Itoa(m.Byte),
+			strconv.Itoa(m.Block.X),
+			strconv.Itoa(m.Block.Y),
+			strconv.FormatBool(m.Reachable),
+			strconv.Itoa(m.Length),
+			strconv.FormatBool(m.Rerouted),
+		})
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
+
+var timelinePalette = color.Palette{
+	color.RGBA{0x0f, 0x0f, 0x23, 0xff}, // open memory
+	color.RGBA{0x66, 0x66, 0x77, 0xff}, // fallen bytes
+	color.RGBA{0xff, 0xff, 0x66, 0xff}, // the path
+	color.RGBA{0xff, 0x44, 0x44, 0xff}, // the byte that moved the path
+}
+
+const (
+	openIndex = iota
+	blockIndex
+	pathIndex
+	fallingIndex
+)
+
+// WriteGIF animates the path rerouting. There's a frame for the first byte
+// and for every byte that lands on the path, drawn scale pixels to a cell,
+// and the last frame, where the exit is cut off, is held for a few seconds.
+func (t Timeline) WriteGIF(w io.Writer, scale int) error {
+	side := (t.Size + 1) * scale
+	bounds := image.Rect(0, 0, side, side)
+	blocks := image.NewPaletted(bounds, timelinePalette)
+
+	fill := func(img *image.Paletted, loc shared.Coord, index uint8) {
+		for y := loc.Y * scale; y < (loc.Y+1)*scale; y++ {
+			for x := loc.X * scale; x < (loc.X+1)*scale; x++ {
+				img.SetColorIndex(x, y, index)
+			}
+		}
+	}
+
+	animation := &gif.GIF{}
+
+	for i, m := range t.Moments {
+		fill(blocks, m.Block, blockIndex)
+
+		if i > 0 && !m.Rerouted {
+			continue
+		}
+
+		frame := image.NewPaletted(bounds, timelinePalette)
+		copy(frame.Pix, blocks.Pix)
+		for _, loc := range m.Path {
+			fill(frame, loc, pathIndex)
+		}
+		fill(frame, m.Block, fallingIndex)
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 5)
+
+		if !m.Reachable {
+			animation.Delay[len(animation.Delay)-1] = 300
+			break
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	if err := gif.EncodeAll(out, animation); err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
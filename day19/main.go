@@ -1,10 +1,11 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -28,29 +29,22 @@ func main() {
 }
 
 func readInput(filePath string) ([]string, []string) {
-	file, _ := os.Open(filePath)
+	file, err := os.Open(filePath)
+	if err != nil {
+		fmt.Printf("Error opening %s", filePath)
+		return nil, nil
+	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	var towels []string
-	var designs []string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if towels == nil {
-			towels = strings.Split(line, ", ")
-			continue
-		}
-
-		if len(line) == 0 {
-			continue
-		}
-
-		designs = append(designs, line)
+	blocks, err := input.Blocks(file)
+	if err != nil || len(blocks) != 2 {
+		fmt.Printf("Error reading %s", filePath)
+		return nil, nil
 	}
 
+	towels := strings.Split(blocks[0][0], ", ")
+	designs := blocks[1]
+
 	return towels, designs
 }
 
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"maps"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/too-gee/advent-of-code-2024/shared"
@@ -53,3 +56,141 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestHistogram(t *testing.T) {
+	maze := readInput("input_small.txt")
+
+	cases := []struct {
+		maxLength  int
+		minSavings int
+		expected   map[int]int
+	}{
+		{2, 1, map[int]int{2: 14, 4: 14, 6: 2, 8: 4, 10: 2, 12: 3, 20: 1, 36: 1, 38: 1, 40: 1, 64: 1}},
+		{20, 50, map[int]int{50: 32, 52: 31, 54: 29, 56: 39, 58: 25, 60: 23, 62: 20, 64: 19, 66: 12, 68: 14, 70: 12, 72: 22, 74: 4, 76: 3}},
+	}
+
+	for _, c := range cases {
+		histogram := Histogram(Cheats(maze, c.maxLength, c.minSavings))
+		if !maps.Equal(histogram, c.expected) {
+			t.Errorf("%dps cheats: expected %v, got %v", c.maxLength, c.expected, histogram)
+		}
+	}
+}
+
+func TestTopCheats(t *testing.T) {
+	maze := readInput("input_small.txt")
+	cheats := Cheats(maze, 2, 1)
+
+	top := TopCheats(cheats, 3)
+	expected := []Cheat{
+		{Start: shared.Coord{X: 7, Y: 7}, End: shared.Coord{X: 5, Y: 7}, Length: 2, Saving: 64},
+		{Start: shared.Coord{X: 7, Y: 7}, End: shared.Coord{X: 7, Y: 9}, Length: 2, Saving: 40},
+		{Start: shared.Coord{X: 8, Y: 7}, End: shared.Coord{X: 8, Y: 9}, Length: 2, Saving: 38},
+	}
+	if !slices.Equal(top, expected) {
+		t.Errorf("expected %v, got %v", expected, top)
+	}
+
+	if all := TopCheats(cheats, 1000); len(all) != len(cheats) {
+		t.Errorf("expected all %d cheats, got %d", len(cheats), len(all))
+	}
+
+	for _, cheat := range Cheats(maze, 20, 70) {
+		path := cheat.Path()
+		if len(path) != cheat.Length-1 {
+			t.Errorf("%v: expected %d cells through the walls, got %d", cheat, cheat.Length-1, len(path))
+		}
+	}
+}
+
+func gridOf(rows ...string) shared.Grid {
+	grid := shared.Grid{}
+	for _, row := range rows {
+		grid = append(grid, strings.Split(row, ""))
+	}
+
+	return grid
+}
+
+func TestBranchingCheats(t *testing.T) {
+	// day 16's example, which branches, loops and dead ends
+	reindeer := gridOf(
+		"###############",
+		"#.......#....E#",
+		"#.#.###.#.###.#",
+		"#.....#.#...#.#",
+		"#.###.#####.#.#",
+		"#.#.#.......#.#",
+		"#.#.#####.###.#",
+		"#...........#.#",
+		"###.#.#####.#.#",
+		"#...#.....#.#.#",
+		"#.#.#.###.#.#.#",
+		"#.....#...#.#.#",
+		"#.###.#.#.#.#.#",
+		"#S..#.....#...#",
+		"###############",
+	)
+
+	// day 18's example after 12 bytes, racing corner to corner
+	memory := gridOf(
+		"...#...",
+		"..#..#.",
+		"....#..",
+		"...#..#",
+		"..#..#.",
+		".#..#..",
+		"#.#....",
+	)
+
+	cases := []struct {
+		name  string
+		maze  shared.Grid
+		start shared.Coord
+		end   shared.Coord
+	}{
+		{"reindeer", reindeer, reindeer.LocationOf(START), reindeer.LocationOf(END)},
+		{"memory", memory, shared.Coord{X: 0, Y: 0}, shared.Coord{X: 6, Y: 6}},
+	}
+
+	for _, c := range cases {
+		base := c.maze.Distances(c.start, []string{WALL})[c.end]
+		cheats := CheatsBetween(c.maze, c.start, c.end, 2, 1)
+
+		// a 2ps cheat through a wall saves as much as knocking the wall down
+		for y := range c.maze.Height() {
+			for x := range c.maze.Width() {
+				wall := shared.Coord{X: x, Y: y}
+				if c.maze.At(wall) != WALL {
+					continue
+				}
+
+				best := 0
+				for _, cheat := range cheats {
+					if cheat.Length == 2 && adjacent(wall, cheat.Start) && adjacent(wall, cheat.End) {
+						best = max(best, cheat.Saving)
+					}
+				}
+
+				opened := gridOf()
+				for _, row := range c.maze {
+					opened = append(opened, slices.Clone(row))
+				}
+				opened[y][x] = EMPTY
+
+				if saving := base - opened.Distances(c.start, []string{WALL})[c.end]; saving != best {
+					t.Errorf("%s: opening %v saves %d, but the best cheat through it saves %d", c.name, wall, saving, best)
+				}
+			}
+		}
+	}
+
+	// a cut off end leaves nothing to save
+	if cheats := CheatsBetween(gridOf("S.#.E"), shared.Coord{X: 0, Y: 0}, shared.Coord{X: 4, Y: 0}, 2, 1); len(cheats) != 0 {
+		t.Errorf("unreachable end: expected no cheats, got %v", cheats)
+	}
+}
+
+func adjacent(a, b shared.Coord) bool {
+	return abs(a.X-b.X)+abs(a.Y-b.Y) == 1
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+// Cheat is a walk through walls from one track cell to another. Length is
+// the picoseconds it takes and Saving is how many fewer the race takes with it.
+type Cheat struct {
+	Start  shared.Coord
+	End    shared.Coord
+	Length int
+	Saving int
+}
+
+// Path is a way through the walls the cheat could take, every cell between
+// Start and End. It goes across first and then up or down.
+func (c Cheat) Path() []shared.Coord {
+	path := []shared.Coord{}
+	loc := c.Start
+
+	step := func(from, to int) int { return cmp.Compare(to, from) }
+
+	for loc.X != c.End.X {
+		loc.X += step(loc.X, c.End.X)
+		path = append(path, loc)
+	}
+	for loc.Y != c.End.Y {
+		loc.Y += step(loc.Y, c.End.Y)
+		path = append(path, loc)
+	}
+
+	// the last step lands on End, which is track
+	return path[:len(path)-1]
+}
+
+// Cheats lists every cheat up to maxLength picoseconds long that saves at
+// least minSavings on the race from S to E, each start and end pair once,
+// biggest savings first
+func Cheats(maze shared.Grid, maxLength int, minSavings int) []Cheat {
+	return CheatsBetween(maze, maze.LocationOf(START), maze.LocationOf(END), maxLength, minSavings)
+}
+
+// CheatsBetween is Cheats for any grid walled with "#", racing from start to
+// end. The track can branch, dead end or loop: a cheat from a to b saves the
+// fastest race minus the fastest race through it, which is the distance from
+// start to a, the cheat, and the distance from b to end. When end can't be
+// reached without cheating there's no race to save time on.
+func CheatsBetween(maze shared.Grid, start shared.Coord, end shared.Coord, maxLength int, minSavings int) []Cheat {
+	fromStart := maze.Distances(start, []string{WALL})
+	toEnd := maze.Distances(end, []string{WALL})
+
+	cheats := []Cheat{}
+
+	base, ok := fromStart[end]
+	if !ok {
+		return cheats
+	}
+
+	for from, before := range fromStart {
+		for dx := -1 * maxLength; dx <= maxLength; dx++ {
+			for dy := -1 * maxLength; dy <= maxLength; dy++ {
+				to := shared.Coord{X: from.X + dx, Y: from.Y + dy}
+				duringCheat := abs(dx) + abs(dy)
+
+				if (dx == 0 && dy == 0) || duringCheat > maxLength {
+					continue
+				}
+
+				// walls and track cut off from the end have no distance
+				after, ok := toEnd[to]
+				if !ok {
+					continue
+				}
+
+				saving := base - (before + duringCheat + after)
+				if saving > 0 && saving >= minSavings {
+					cheats = append(cheats, Cheat{Start: from, End: to, Length: duringCheat, Saving: saving})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(cheats, compareCheats)
+
+	return cheats
+}
+
+// compareCheats puts bigger savings first, then shorter cheats, then orders
+// by position so the listing is the same every run
+func compareCheats(a, b Cheat) int {
+	return cmp.Or(
+		cmp.Compare(b.Saving, a.Saving),
+		cmp.Compare(a.Length, b.Length),
+		cmp.Compare(a.Start.Y, b.Start.Y),
+		cmp.Compare(a.Start.X, b.Start.X),
+		cmp.Compare(a.End.Y, b.End.Y),
+		cmp.Compare(a.End.X, b.End.X),
+	)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// Histogram counts the cheats that save each number of picoseconds
+func Histogram(cheats []Cheat) map[int]int {
+	counts := map[int]int{}
+	for _, cheat := range cheats {
+		counts[cheat.Saving]++
+	}
+
+	return counts
+}
+
+// TopCheats is the n cheats that save the most, from a listing Cheats made
+func TopCheats(cheats []Cheat, n int) []Cheat {
+	return cheats[:min(n, len(cheats))]
+}
+
+// DrawCheat prints the maze with the cheat's way through the walls marked
+func DrawCheat(maze shared.Grid, cheat Cheat) {
+	maze.Draw(
+		map[string]string{START: "🟢", END: "🔴"},
+		map[string][]shared.Coord{"🟨": cheat.Path(), "🟩": {cheat.Start}, "🟥": {cheat.End}},
+	)
+}
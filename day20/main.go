@@ -2,8 +2,8 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"math"
 	"os"
 	"slices"
 	"strings"
@@ -12,16 +12,45 @@ import (
 )
 
 func main() {
+	top := flag.Int("top", 0, "list the cheats that save the most, up to this many, draw the best and exit")
+	histogram := flag.Bool("histogram", false, "count the cheats by how much they save and exit")
+	cheatLength := flag.Int("cheat", 20, "longest cheat, in picoseconds, for -top and -histogram")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
 
 	maze := readInput(fileName)
 
+	if *top > 0 {
+		cheats := TopCheats(Cheats(maze, *cheatLength, 1), *top)
+		for _, cheat := range cheats {
+			fmt.Printf("%v -> %v: %dps, saves %dps\n", cheat.Start, cheat.End, cheat.Length, cheat.Saving)
+		}
+		if len(cheats) > 0 {
+			DrawCheat(maze, cheats[0])
+		}
+		return
+	}
+
+	if *histogram {
+		counts := Histogram(Cheats(maze, *cheatLength, 1))
+		savings := []int{}
+		for saving := range counts {
+			savings = append(savings, saving)
+		}
+		slices.Sort(savings)
+		for _, saving := range savings {
+			fmt.Printf("%d cheats save %dps\n", counts[saving], saving)
+		}
+		return
+	}
+
 	// Part 1
 	if strings.HasSuffix(fileName, "small.txt") {
 		fmt.Printf("Part 1: %d cheats save over 64us with 2us cheats\n", Solve(maze, 64, 2))
@@ -51,65 +80,10 @@ func readInput(filePath string) shared.Grid {
 	return grid
 }
 
+// Solve counts the cheats up to cheatLength picoseconds long that save at
+// least minSavings
 func Solve(maze shared.Grid, minSavings int, cheatLength int) int {
-	remaining := GetRemainingLengths(maze)
-
-	cheats := 0
-	passable := []string{EMPTY, END}
-
-	for start, value := range remaining {
-		for dx := -1 * cheatLength; dx <= cheatLength; dx++ {
-			for dy := -1 * cheatLength; dy <= cheatLength; dy++ {
-				end := shared.Coord{X: start.X + dx, Y: start.Y + dy}
-				duringCheat := int(math.Abs(float64(dx)) + math.Abs(float64(dy)))
-
-				if !(dx == 0 && dy == 0) && // is not the start
-					duringCheat <= cheatLength && // is within cheat range
-					maze.Contains(end) && // is within maze
-					slices.Contains(passable, maze.At(end)) && // is passable
-					value-remaining[end]-duringCheat >= minSavings { // is a savings
-					cheats++
-				}
-			}
-		}
-	}
-
-	return cheats
-}
-
-func MazeLength(maze shared.Grid) int {
-	totalLength := 1
-
-	for _, row := range maze {
-		for _, cell := range row {
-			if cell == EMPTY {
-				totalLength++
-			}
-		}
-	}
-
-	return totalLength
-}
-
-func GetRemainingLengths(maze shared.Grid) map[shared.Coord]int {
-	remaining := map[shared.Coord]int{}
-	crawler := []shared.Coord{maze.LocationOf(START)}
-
-	for i := MazeLength(maze); i >= 0; i-- {
-		cur := crawler[len(crawler)-1]
-		remaining[cur] = i
-
-		for _, neighbor := range maze.Neighbors(cur, []string{WALL}) {
-			if slices.Contains(crawler, neighbor) {
-				continue
-			}
-
-			crawler = append(crawler, neighbor)
-			break
-		}
-	}
-
-	return remaining
+	return len(Cheats(maze, cheatLength, minSavings))
 }
 
 const WALL = "#"
@@ -1,6 +1,14 @@
 package main
 
 import (
+	"errors"
+	"maps"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +35,404 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestCircuit(t *testing.T) {
+	conns := readInput("input_small.txt")
+	circuit, err := NewCircuit(conns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// z00 = x00 AND y00, z01 = x01 XOR y01, z02 = x02 OR y02
+	for _, c := range [][3]uint64{{7, 2, 4}, {1, 1, 1}, {6, 0, 6}, {0, 0, 0}} {
+		if z := circuit.Evaluate(c[0], c[1]); z != c[2] {
+			t.Errorf("x=%d y=%d: expected %d, got %d", c[0], c[1], c[2], z)
+		}
+	}
+
+	// evaluating the real input over and over must leave the netlist alone
+	conns = readInput("input.txt")
+	before := maps.Clone(conns)
+
+	circuit, err = NewCircuit(conns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(circuit.X) != 45 || len(circuit.Z) != 46 {
+		t.Fatalf("expected 45 input and 46 output bits, got %d and %d", len(circuit.X), len(circuit.Z))
+	}
+
+	random := rand.New(rand.NewPCG(24, 2024))
+	for range 100 {
+		x, y := random.Uint64N(1<<45), random.Uint64N(1<<45)
+		z := circuit.EvaluateBig(new(big.Int).SetUint64(x), new(big.Int).SetUint64(y))
+
+		if !z.IsUint64() || z.Uint64() != circuit.Evaluate(x, y) {
+			t.Errorf("x=%d y=%d: Evaluate and EvaluateBig disagree", x, y)
+		}
+	}
+
+	if !maps.Equal(conns, before) {
+		t.Errorf("evaluating the circuit changed the netlist")
+	}
+
+	cyclic := Connections{
+		"x00": {value: 1},
+		"a":   {operator: "AND", operand1: "x00", operand2: "b"},
+		"b":   {operator: "OR", operand1: "a", operand2: "x00"},
+	}
+
+	var cycle *CycleError
+	if _, err := NewCircuit(cyclic); !errors.As(err, &cycle) || !slices.Equal(cycle.Wires, []string{"a", "b", "a"}) {
+		t.Errorf("expected the cycle a -> b -> a, got %v", err)
+	}
+
+	dangling := Connections{"z00": {operator: "AND", operand1: "x00", operand2: "y00"}}
+	if _, err := NewCircuit(dangling); err == nil {
+		t.Errorf("expected an error for undriven wires")
+	}
+}
+
+func TestVerifyAdder(t *testing.T) {
+	conns := readInput("input.txt")
+	random := rand.New(rand.NewPCG(24, 2024))
+
+	broken, err := NewCircuit(conns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failure *AdderError
+	if err := broken.VerifyAdder(100, random); !errors.As(err, &failure) {
+		t.Errorf("expected the input circuit to fail, got %v", err)
+	}
+
+	// z07 is the carry out of bit 6
+	if bad := broken.FirstBadBit(); bad != 6 {
+		t.Errorf("expected bit 6 to be the first bad bit, got %d", bad)
+	}
+
+	swaps := []Swap{{"bjm", "z07"}, {"hsw", "z13"}, {"skf", "z18"}, {"nvr", "wkr"}}
+
+	apply := func(swaps []Swap) *Circuit {
+		circuit := broken
+		for _, swap := range swaps {
+			if circuit, err = circuit.Swap(swap[0], swap[1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return circuit
+	}
+
+	if err := apply(swaps).VerifyAdder(1000, random); err != nil {
+		t.Errorf("expected the repaired circuit to add, got %v", err)
+	}
+
+	// a missed swap or a spurious one must both be caught
+	if err := apply(swaps[:3]).VerifyAdder(1000, random); err == nil {
+		t.Errorf("expected a missed swap to fail verification")
+	}
+
+	if err := apply(append(slices.Clone(swaps), Swap{"z20", "z21"})).VerifyAdder(1000, random); err == nil {
+		t.Errorf("expected a spurious swap to fail verification")
+	}
+
+	// the pattern walk should agree with the verified repair
+	wires := []string{}
+	for _, swap := range swaps {
+		wires = append(wires, swap[:]...)
+	}
+	slices.Sort(wires)
+
+	if suspects := Suspects(conns); !slices.Equal(suspects, wires) {
+		t.Errorf("expected suspects %v, got %v", wires, suspects)
+	}
+
+	if _, err := broken.Swap("x00", "z00"); err == nil {
+		t.Errorf("expected an error swapping an input wire")
+	}
+
+	// bit 10 with two swaps, its half adder's outputs and its carry out with
+	// bit 11's carry: neither one alone fixes it, so the search has to go
+	// through a swap that leaves the lowest broken bit where it was
+	repaired := readInput("input.txt")
+	for _, swap := range swaps {
+		repaired[swap[0]], repaired[swap[1]] = repaired[swap[1]], repaired[swap[0]]
+	}
+	sum, carry := repaired.Find("x10", "y10", "XOR"), repaired.Find("x10", "y10", "AND")
+	carryOut, nextCarry := repaired.Find(carry, "", "OR"), repaired.Find("x11", "y11", "AND")
+	twice := Connections{}
+	maps.Copy(twice, repaired)
+	twice[sum], twice[carry] = twice[carry], twice[sum]
+	twice[carryOut], twice[nextCarry] = twice[nextCarry], twice[carryOut]
+	expected := []Swap{{min(sum, carry), max(sum, carry)}, {min(carryOut, nextCarry), max(carryOut, nextCarry)}}
+
+	circuit, _ := NewCircuit(twice)
+	for _, swap := range expected {
+		if undone, _ := circuit.Swap(swap[0], swap[1]); undone.FirstBadBit() != 10 {
+			t.Errorf("expected undoing only %v to leave bit 10 broken, got %d", swap, undone.FirstBadBit())
+		}
+	}
+
+	repairs, err := RepairAdder(twice, 2)
+	slices.SortFunc(repairs, func(a, b Swap) int { return strings.Compare(a[0], b[0]) })
+	slices.SortFunc(expected, func(a, b Swap) int { return strings.Compare(a[0], b[0]) })
+	if err != nil || !slices.Equal(repairs, expected) {
+		t.Errorf("expected repairs %v, got %v (%v)", expected, repairs, err)
+	}
+
+	small, _ := NewCircuit(readInput("input_small.txt"))
+	if err := small.VerifyAdder(10, random); err == nil {
+		t.Errorf("expected input_small.txt to be rejected as an adder")
+	}
+}
+
+func TestExport(t *testing.T) {
+	var sb strings.Builder
+
+	if err := readInput("input_small.txt").WriteVerilog(&sb, "small"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `module small (
+    input  wire x00,
+    input  wire x01,
+    input  wire x02,
+    input  wire y00,
+    input  wire y01,
+    input  wire y02,
+    output wire z00,
+    output wire z01,
+    output wire z02
+);
+
+    and g_z00 (z00, x00, y00);
+    xor g_z01 (z01, x01, y01);
+    or g_z02 (z02, x02, y02);
+endmodule
+`
+
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+
+	// wires named after keywords have to be escaped
+	sb.Reset()
+	conns := Connections{"x00": {}, "y00": {}, "and": {operator: "AND", operand1: "x00", operand2: "y00"}, "z00": {operator: "OR", operand1: "and", operand2: "x00"}}
+	conns.WriteVerilog(&sb, "keywords")
+
+	if !strings.Contains(sb.String(), "    wire \\and ;") || !strings.Contains(sb.String(), "or g_z00 (z00, \\and , x00);") {
+		t.Errorf("expected \\and to be escaped, got:\n%s", sb.String())
+	}
+
+	sb.Reset()
+	conns = readInput("input.txt")
+	suspects := Suspects(conns)
+
+	if err := conns.WriteDOT(&sb, suspects); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := sb.String()
+	gates := 0
+	for _, conn := range conns {
+		if conn.operator != "" {
+			gates++
+		}
+	}
+
+	if edges := strings.Count(dot, " -> "); edges != 2*gates {
+		t.Errorf("expected %d edges, got %d", 2*gates, edges)
+	}
+
+	if highlighted := strings.Count(dot, "color=red"); highlighted != len(suspects) {
+		t.Errorf("expected %d highlighted wires, got %d", len(suspects), highlighted)
+	}
+
+	if !strings.Contains(dot, `"z07" [label="z07\nOR", fillcolor=palegreen, shape=doubleoctagon, color=red, penwidth=3];`) {
+		t.Errorf("expected z07 to be drawn as a suspect OR output")
+	}
+}
+
+func TestParseConnections(t *testing.T) {
+	cases := []struct {
+		source string
+		err    string
+	}{
+		{"x00: 1\ny00: 0\n\nx -> y\n", "line 4: unknown gate \"x\""},
+		{"a FOO b -> z\n", "line 1: unknown gate \"a FOO b\""},
+		{"NOT a b -> z\n", "line 1: unknown gate \"NOT a b\""},
+		{"a NOT b -> z\n", "line 1: unknown gate \"a NOT b\""},
+		{"MUX s a -> z\n", "line 1: unknown gate \"MUX s a\""},
+		{"a AND b ->\n", "line 1: expected \"<gate> -> <wire>\", got \"a AND b ->\""},
+		{"x00: one\n", "line 1: invalid value in \"x00: one\""},
+	}
+
+	for _, c := range cases {
+		if _, err := parseConnections(strings.NewReader(c.source)); err == nil || err.Error() != c.err {
+			t.Errorf("%q: expected error %q, got %v", c.source, c.err, err)
+		}
+	}
+
+	conns, err := parseConnections(strings.NewReader("a: 1\nb: 0\n\na XOR b -> z00\nNOT a -> z01\n"))
+	if err != nil || conns["z00"] != (Connection{operator: "XOR", operand1: "a", operand2: "b"}) || conns["z01"] != (Connection{operator: "NOT", operand1: "a"}) {
+		t.Errorf("unexpected connections %v (%v)", conns, err)
+	}
+}
+
+func TestGateLibrary(t *testing.T) {
+	source := `a: 0
+b: 0
+s: 0
+
+NOT a -> not
+a NAND b -> nand
+a NOR b -> nor
+a XNOR b -> xnor
+MUX s a b -> mux
+`
+
+	fileName := filepath.Join(t.TempDir(), "gates.txt")
+	if err := os.WriteFile(fileName, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	conns := readInput(fileName)
+	circuit, err := NewCircuit(conns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, inputs := range [][3]uint8{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 1}, {1, 0, 1}} {
+		a, b, sel := inputs[0], inputs[1], inputs[2]
+		values := circuit.Simulate(map[string]uint8{"a": a, "b": b, "s": sel})
+
+		mux := a
+		if sel == 1 {
+			mux = b
+		}
+
+		expected := map[string]uint8{"not": 1 ^ a, "nand": 1 ^ a&b, "nor": 1 ^ (a | b), "xnor": 1 ^ a ^ b, "mux": mux}
+		for wire, value := range expected {
+			if values[wire] != value {
+				t.Errorf("a=%d b=%d s=%d: expected %s=%d, got %d", a, b, sel, wire, value, values[wire])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if err := conns.WriteVerilog(&sb, "gates"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"    not g_not (\\not , a);", "    xnor g_xnor (\\xnor , a, b);", "    assign mux = s ? b : a;"} {
+		if !strings.Contains(sb.String(), line) {
+			t.Errorf("expected %q in:\n%s", line, sb.String())
+		}
+	}
+}
+
+func TestEvaluateMany(t *testing.T) {
+	circuit, _ := NewCircuit(readInput("input.txt"))
+	random := rand.New(rand.NewPCG(41, 2024))
+
+	xs, ys := make([]uint64, 1000), make([]uint64, 1000)
+	for i := range xs {
+		xs[i], ys[i] = random.Uint64N(1<<45), random.Uint64N(1<<45)
+	}
+
+	for i, z := range circuit.EvaluateMany(xs, ys) {
+		expected := circuit.EvaluateBig(new(big.Int).SetUint64(xs[i]), new(big.Int).SetUint64(ys[i]))
+		if expected.Uint64() != z {
+			t.Fatalf("x=%d y=%d: expected %d, got %d", xs[i], ys[i], expected, z)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	circuit, _ := NewCircuit(readInput("input.txt"))
+	random := rand.New(rand.NewPCG(41, 2024))
+
+	xs, ys := make([]uint64, 4096), make([]uint64, 4096)
+	for i := range xs {
+		xs[i], ys[i] = random.Uint64N(1<<45), random.Uint64N(1<<45)
+	}
+
+	b.Run("one-at-a-time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range xs {
+				circuit.Evaluate(xs[j], ys[j])
+			}
+		}
+	})
+
+	b.Run("lanes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			circuit.EvaluateMany(xs, ys)
+		}
+	})
+}
+
+func TestEquivalent(t *testing.T) {
+	random := rand.New(rand.NewPCG(42, 2024))
+
+	for _, n := range []int{1, 4, 8, 45} {
+		reference, err := NewCircuit(RippleCarryAdder(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := reference.VerifyAdder(1000, random); err != nil {
+			t.Errorf("%d bits: reference adder doesn't add: %v", n, err)
+		}
+
+		if err := reference.Equivalent(reference); err != nil {
+			t.Errorf("%d bits: expected the reference to match itself, got %v", n, err)
+		}
+	}
+
+	// small widths are checked exhaustively
+	reference, _ := NewCircuit(RippleCarryAdder(4))
+	broken, _ := reference.Swap("z02", "car02")
+
+	var mismatch *Mismatch
+	if err := reference.Equivalent(broken); !errors.As(err, &mismatch) || mismatch.Output != "z02" {
+		t.Errorf("expected z02 to differ, got %v", err)
+	} else {
+		checkMismatch(t, reference, broken, mismatch)
+	}
+
+	// the puzzle's adder needs decision diagrams
+	conns := readInput("input.txt")
+	circuit, _ := NewCircuit(conns)
+	reference, _ = NewCircuit(RippleCarryAdder(45))
+
+	if err := circuit.Equivalent(reference); !errors.As(err, &mismatch) || mismatch.Output != "z07" {
+		t.Errorf("expected z07 to be the first difference, got %v", err)
+	} else {
+		checkMismatch(t, circuit, reference, mismatch)
+	}
+
+	for _, swap := range []Swap{{"bjm", "z07"}, {"hsw", "z13"}, {"skf", "z18"}, {"nvr", "wkr"}} {
+		circuit, _ = circuit.Swap(swap[0], swap[1])
+	}
+
+	if err := circuit.Equivalent(reference); err != nil {
+		t.Errorf("expected the repaired adder to match the reference, got %v", err)
+	}
+
+	if err := circuit.Equivalent(broken); err == nil {
+		t.Errorf("expected circuits of different widths to be rejected")
+	}
+}
+
+// checkMismatch confirms a counterexample by simulating it
+func checkMismatch(t *testing.T, a, b *Circuit, mismatch *Mismatch) {
+	t.Helper()
+
+	got, want := a.Simulate(mismatch.Inputs)[mismatch.Output], b.Simulate(mismatch.Inputs)[mismatch.Output]
+	if got == want || got != mismatch.A || want != mismatch.B {
+		t.Errorf("counterexample %v doesn't split %s: %d and %d", mismatch.Inputs, mismatch.Output, got, want)
+	}
+}
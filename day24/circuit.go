@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Gate computes one wire from others
+type Gate struct {
+	Output   string
+	Operator string
+	Operands []string
+}
+
+// gate is a Gate with its wires resolved to indexes. Operands past the
+// operator's arity point at wire 0 and are ignored.
+type gate struct {
+	output   int
+	operator string
+	arity    int
+	eval     func(a, b, c uint64) uint64
+	operands [3]int
+}
+
+// Circuit is a netlist prepared for repeated evaluation. The gates are kept in
+// topological order, so one pass settles every wire and the netlist it was
+// built from is never touched.
+type Circuit struct {
+	Wires []string
+	Gates []Gate
+
+	// Initial holds the values the input file gave its input wires
+	Initial map[string]uint8
+
+	// X, Y and Z are the wire indexes of each bit, least significant first
+	X []int
+	Y []int
+	Z []int
+
+	index map[string]int
+	gates []gate
+}
+
+// CycleError reports gates whose outputs feed back into themselves
+type CycleError struct {
+	Wires []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("wires form a cycle: %s", strings.Join(e.Wires, " -> "))
+}
+
+// NewCircuit sorts the gates so each comes after the gates it reads from. It
+// fails on unknown operators, if a gate reads a wire nothing drives, or if
+// the gates form a cycle.
+func NewCircuit(conns Connections) (*Circuit, error) {
+	c := &Circuit{Initial: map[string]uint8{}, index: map[string]int{}}
+
+	for _, wire := range conns.Keys() {
+		c.index[wire] = len(c.Wires)
+		c.Wires = append(c.Wires, wire)
+
+		if conns[wire].operator == "" {
+			c.Initial[wire] = conns[wire].value
+		}
+	}
+
+	gates := []gate{}
+
+	for _, wire := range c.Wires {
+		conn := conns[wire]
+		if conn.operator == "" {
+			continue
+		}
+
+		operator, ok := Operators[conn.operator]
+		if !ok {
+			return nil, fmt.Errorf("gate %s has unknown operator %s", wire, conn.operator)
+		}
+
+		g := gate{output: c.index[wire], operator: conn.operator, arity: operator.Arity, eval: operator.Eval}
+
+		for i, operand := range conn.operands() {
+			if _, ok := conns[operand]; !ok {
+				return nil, fmt.Errorf("gate %s reads %s, which nothing drives", wire, operand)
+			}
+			g.operands[i] = c.index[operand]
+		}
+
+		gates = append(gates, g)
+	}
+
+	if err := c.setGates(gates); err != nil {
+		return nil, err
+	}
+
+	c.X = c.bus("x")
+	c.Y = c.bus("y")
+	c.Z = c.bus("z")
+
+	return c, nil
+}
+
+// setGates puts the gates in topological order, depth-first, emitting each
+// gate after everything it depends on
+func (c *Circuit) setGates(gates []gate) error {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	drivers := make([]int, len(c.Wires))
+	for i := range drivers {
+		drivers[i] = -1
+	}
+	for i, g := range gates {
+		drivers[g.output] = i
+	}
+
+	state := make([]uint8, len(c.Wires))
+	path := []int{}
+	sorted := make([]gate, 0, len(gates))
+
+	var visit func(wire int) error
+	visit = func(wire int) error {
+		if drivers[wire] < 0 || state[wire] == done {
+			return nil
+		}
+
+		if state[wire] == visiting {
+			cycle := []string{}
+			for _, i := range path[slices.Index(path, wire):] {
+				cycle = append(cycle, c.Wires[i])
+			}
+			return &CycleError{Wires: append(cycle, c.Wires[wire])}
+		}
+
+		state[wire] = visiting
+		path = append(path, wire)
+
+		g := gates[drivers[wire]]
+		for _, operand := range g.operands[:g.arity] {
+			if err := visit(operand); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[wire] = done
+		sorted = append(sorted, g)
+
+		return nil
+	}
+
+	for wire := range c.Wires {
+		if err := visit(wire); err != nil {
+			return err
+		}
+	}
+
+	c.gates = sorted
+	c.Gates = make([]Gate, len(sorted))
+	names := make([]string, 0, 3*len(sorted))
+
+	for i, g := range sorted {
+		start := len(names)
+		for _, operand := range g.operands[:g.arity] {
+			names = append(names, c.Wires[operand])
+		}
+
+		c.Gates[i] = Gate{Output: c.Wires[g.output], Operator: g.operator, Operands: names[start:len(names):len(names)]}
+	}
+
+	return nil
+}
+
+// Swap returns a copy of the circuit with the outputs of two gates exchanged
+func (c *Circuit) Swap(a, b string) (*Circuit, error) {
+	ia, okA := c.index[a]
+	ib, okB := c.index[b]
+	if !okA || !okB {
+		return nil, fmt.Errorf("can't swap unknown wires %s and %s", a, b)
+	}
+
+	gates := slices.Clone(c.gates)
+	swapped := 0
+
+	for i := range gates {
+		switch gates[i].output {
+		case ia:
+			gates[i].output = ib
+			swapped++
+		case ib:
+			gates[i].output = ia
+			swapped++
+		}
+	}
+
+	if swapped != 2 {
+		return nil, fmt.Errorf("can't swap %s and %s, both must be gate outputs", a, b)
+	}
+
+	swappedCircuit := *c
+	if err := swappedCircuit.setGates(gates); err != nil {
+		return nil, err
+	}
+
+	return &swappedCircuit, nil
+}
+
+// bus finds the wires named prefix00, prefix01, ... in bit order
+func (c *Circuit) bus(prefix string) []int {
+	wires := []int{}
+
+	for i := 0; ; i++ {
+		wire, ok := c.index[fmt.Sprintf("%s%02d", prefix, i)]
+		if !ok {
+			return wires
+		}
+		wires = append(wires, wire)
+	}
+}
+
+// Lanes is how many inputs one pass over the gates evaluates. Every wire holds
+// a uint64, and bit i of each one belongs to the i-th input.
+const Lanes = 64
+
+// Simulate settles every wire. Input wires missing from inputs are 0.
+func (c *Circuit) Simulate(inputs map[string]uint8) map[string]uint8 {
+	values := make([]uint64, len(c.Wires))
+	for wire, value := range inputs {
+		if i, ok := c.index[wire]; ok {
+			values[i] = uint64(value & 1)
+		}
+	}
+
+	c.settle(values)
+
+	result := make(map[string]uint8, len(c.Wires))
+	for i, wire := range c.Wires {
+		result[wire] = uint8(values[i] & 1)
+	}
+
+	return result
+}
+
+func (c *Circuit) settle(values []uint64) {
+	for _, g := range c.gates {
+		values[g.output] = g.eval(values[g.operands[0]], values[g.operands[1]], values[g.operands[2]])
+	}
+}
+
+// EvaluateMany is Evaluate for each pair xs[i], ys[i]. The pairs are packed
+// into lanes, so it takes one pass over the gates for every 64 of them.
+func (c *Circuit) EvaluateMany(xs, ys []uint64) []uint64 {
+	zs := make([]uint64, len(xs))
+	values := make([]uint64, len(c.Wires))
+
+	for start := 0; start < len(xs); start += Lanes {
+		end := min(start+Lanes, len(xs))
+
+		for bit, wire := range c.X {
+			values[wire] = pack(xs[start:end], bit)
+		}
+		for bit, wire := range c.Y {
+			values[wire] = pack(ys[start:end], bit)
+		}
+
+		c.settle(values)
+
+		for bit, wire := range c.Z {
+			for lane := range end - start {
+				zs[start+lane] |= (values[wire] >> lane & 1) << bit
+			}
+		}
+	}
+
+	return zs
+}
+
+// pack gathers one bit of each number into a lane word
+func pack(numbers []uint64, bit int) uint64 {
+	var word uint64
+	for lane, n := range numbers {
+		word |= (n >> bit & 1) << lane
+	}
+
+	return word
+}
+
+// Evaluate sets the x and y wires from the bits of x and y and returns the z
+// wires as a number. Bits past the 64th are dropped, see EvaluateBig.
+func (c *Circuit) Evaluate(x, y uint64) uint64 {
+	return c.EvaluateMany([]uint64{x}, []uint64{y})[0]
+}
+
+// EvaluateBig is Evaluate for buses of any width
+func (c *Circuit) EvaluateBig(x, y *big.Int) *big.Int {
+	values := make([]uint64, len(c.Wires))
+
+	for bit, wire := range c.X {
+		values[wire] = uint64(x.Bit(bit))
+	}
+	for bit, wire := range c.Y {
+		values[wire] = uint64(y.Bit(bit))
+	}
+
+	c.settle(values)
+
+	z := new(big.Int)
+	for bit, wire := range c.Z {
+		z.SetBit(z, bit, uint(values[wire]&1))
+	}
+
+	return z
+}
+
+// InitialXY reads the x and y numbers the input file set up
+func (c *Circuit) InitialXY() (*big.Int, *big.Int) {
+	read := func(wires []int) *big.Int {
+		n := new(big.Int)
+		for bit, wire := range wires {
+			n.SetBit(n, bit, uint(c.Initial[c.Wires[wire]]))
+		}
+		return n
+	}
+
+	return read(c.X), read(c.Y)
+}
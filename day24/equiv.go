@@ -0,0 +1,360 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RippleCarryAdder builds a reference n-bit adder out of AND, OR and XOR
+// gates, wired the way the puzzle's adders are: a half adder for bit 0 and a
+// full adder for every bit after it, with the last carry driving z[n]
+func RippleCarryAdder(n int) Connections {
+	conns := Connections{}
+	wire := func(prefix string, bit int) string { return fmt.Sprintf("%s%02d", prefix, bit) }
+	gate := func(output, operator, a, b string) {
+		conns[output] = Connection{operator: operator, operand1: a, operand2: b}
+	}
+
+	carry := ""
+
+	for bit := range n {
+		x, y := wire("x", bit), wire("y", bit)
+		conns[x], conns[y] = Connection{}, Connection{}
+
+		next := wire("car", bit)
+		if bit == n-1 {
+			next = wire("z", n)
+		}
+
+		if bit == 0 {
+			gate(wire("z", bit), "XOR", x, y)
+			gate(next, "AND", x, y)
+		} else {
+			gate(wire("sum", bit), "XOR", x, y)
+			gate(wire("gen", bit), "AND", x, y)
+			gate(wire("prp", bit), "AND", wire("sum", bit), carry)
+			gate(wire("z", bit), "XOR", wire("sum", bit), carry)
+			gate(next, "OR", wire("gen", bit), wire("prp", bit))
+		}
+
+		carry = next
+	}
+
+	return conns
+}
+
+// Mismatch is a counterexample: an input assignment under which two circuits
+// drive an output differently. Inputs that aren't listed are 0.
+type Mismatch struct {
+	Output string
+	Inputs map[string]uint8
+	A      uint8
+	B      uint8
+}
+
+func (m *Mismatch) Error() string {
+	set := []string{}
+	for _, wire := range sortInputs(mapKeys(m.Inputs)) {
+		if m.Inputs[wire] == 1 {
+			set = append(set, wire)
+		}
+	}
+
+	return fmt.Sprintf("%s is %d in one circuit and %d in the other when only {%s} are 1", m.Output, m.A, m.B, strings.Join(set, " "))
+}
+
+func mapKeys(m map[string]uint8) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// ExhaustiveLimit is the most inputs Equivalent will simulate every
+// assignment of. Larger circuits are compared with decision diagrams.
+const ExhaustiveLimit = 16
+
+// Equivalent checks that two circuits drive their z outputs identically for
+// every assignment of their inputs. When they don't, it returns a *Mismatch
+// for the lowest differing output.
+func (c *Circuit) Equivalent(other *Circuit) error {
+	inputs := c.inputs()
+	if theirs := other.inputs(); !slices.Equal(inputs, theirs) {
+		return fmt.Errorf("circuits have different inputs: %v and %v", inputs, theirs)
+	}
+
+	if len(c.Z) != len(other.Z) {
+		return fmt.Errorf("circuits have %d and %d z outputs", len(c.Z), len(other.Z))
+	}
+
+	if len(inputs) <= ExhaustiveLimit {
+		return c.equivalentExhaustive(other, inputs)
+	}
+
+	return c.equivalentBDD(other, inputs)
+}
+
+// inputs lists the wires no gate drives, ordered for the decision diagrams
+func (c *Circuit) inputs() []string {
+	driven := make([]bool, len(c.Wires))
+	for _, g := range c.gates {
+		driven[g.output] = true
+	}
+
+	inputs := []string{}
+	for i, wire := range c.Wires {
+		if !driven[i] {
+			inputs = append(inputs, wire)
+		}
+	}
+
+	return sortInputs(inputs)
+}
+
+// sortInputs orders wires by their bit number and then by name, so x00, y00,
+// x01, y01, ... Interleaving the bits keeps an adder's diagrams small.
+func sortInputs(wires []string) []string {
+	bit := func(wire string) int {
+		digits := strings.TrimLeft(wire, "abcdefghijklmnopqrstuvwxyz")
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+
+	slices.SortFunc(wires, func(a, b string) int {
+		if bit(a) != bit(b) {
+			return bit(a) - bit(b)
+		}
+		return strings.Compare(a, b)
+	})
+
+	return wires
+}
+
+// equivalentExhaustive runs both circuits on every assignment, 64 at a time,
+// remembering the first assignment that splits each output
+func (c *Circuit) equivalentExhaustive(other *Circuit, inputs []string) error {
+	total := uint64(1) << len(inputs)
+	var first *Mismatch
+	firstOutput := len(c.Z)
+
+	ours, theirs := make([]uint64, len(c.Wires)), make([]uint64, len(other.Wires))
+
+	for start := uint64(0); start < total && firstOutput > 0; start += Lanes {
+		lanes := min(Lanes, total-start)
+
+		for j, wire := range inputs {
+			var word uint64
+			for lane := range lanes {
+				word |= ((start + lane) >> j & 1) << lane
+			}
+			ours[c.index[wire]] = word
+			theirs[other.index[wire]] = word
+		}
+
+		c.settle(ours)
+		other.settle(theirs)
+
+		live := uint64(1)<<lanes - 1
+		if lanes == 64 {
+			live = ^uint64(0)
+		}
+
+		for bit := range firstOutput {
+			diff := (ours[c.Z[bit]] ^ theirs[other.Z[bit]]) & live
+			if diff == 0 {
+				continue
+			}
+
+			lane := uint64(0)
+			for diff>>lane&1 == 0 {
+				lane++
+			}
+
+			assignment := map[string]uint8{}
+			for j, wire := range inputs {
+				assignment[wire] = uint8((start + lane) >> j & 1)
+			}
+
+			firstOutput = bit
+			first = &Mismatch{
+				Output: c.Wires[c.Z[bit]],
+				Inputs: assignment,
+				A:      uint8(ours[c.Z[bit]] >> lane & 1),
+				B:      uint8(theirs[other.Z[bit]] >> lane & 1),
+			}
+			break
+		}
+	}
+
+	if first != nil {
+		return first
+	}
+
+	return nil
+}
+
+// bdd is a reduced ordered binary decision diagram manager. Node 0 is false
+// and node 1 is true. Every function has exactly one node, so two circuits
+// compute the same output exactly when they end on the same node.
+type bdd struct {
+	nodes  []bddNode
+	unique map[bddNode]int
+	cache  map[[3]int]int
+}
+
+type bddNode struct {
+	level int
+	low   int
+	high  int
+}
+
+func newBDD(variables int) *bdd {
+	// the terminals sit below every variable
+	return &bdd{
+		nodes:  []bddNode{{level: variables}, {level: variables}},
+		unique: map[bddNode]int{},
+		cache:  map[[3]int]int{},
+	}
+}
+
+func (b *bdd) variable(level int) int {
+	return b.node(level, 0, 1)
+}
+
+func (b *bdd) node(level, low, high int) int {
+	if low == high {
+		return low
+	}
+
+	n := bddNode{level, low, high}
+	if id, ok := b.unique[n]; ok {
+		return id
+	}
+
+	b.nodes = append(b.nodes, n)
+	b.unique[n] = len(b.nodes) - 1
+
+	return len(b.nodes) - 1
+}
+
+// ite is "if f then g else h", which every gate can be built from
+func (b *bdd) ite(f, g, h int) int {
+	switch {
+	case f == 1:
+		return g
+	case f == 0:
+		return h
+	case g == h:
+		return g
+	case g == 1 && h == 0:
+		return f
+	}
+
+	key := [3]int{f, g, h}
+	if result, ok := b.cache[key]; ok {
+		return result
+	}
+
+	level := min(b.nodes[f].level, b.nodes[g].level, b.nodes[h].level)
+	cofactor := func(u int, high bool) int {
+		if b.nodes[u].level != level {
+			return u
+		}
+		if high {
+			return b.nodes[u].high
+		}
+		return b.nodes[u].low
+	}
+
+	result := b.node(level,
+		b.ite(cofactor(f, false), cofactor(g, false), cofactor(h, false)),
+		b.ite(cofactor(f, true), cofactor(g, true), cofactor(h, true)))
+
+	b.cache[key] = result
+
+	return result
+}
+
+// gate builds a gate's function from its operator's truth table, found by
+// running Eval on lanes that hold every combination of three inputs
+func (b *bdd) gate(g gate, values []int) int {
+	table := g.eval(0xF0, 0xCC, 0xAA)
+	a, x, y := values[g.operands[0]], values[g.operands[1]], values[g.operands[2]]
+
+	bit := func(i int) int { return int(table >> i & 1) }
+	third := func(i int) int { return b.ite(y, bit(i+1), bit(i)) }
+	second := func(i int) int { return b.ite(x, third(i+2), third(i)) }
+
+	return b.ite(a, second(4), second(0))
+}
+
+// satisfy finds an assignment of the variables that makes f true
+func (b *bdd) satisfy(f int) map[int]uint8 {
+	assignment := map[int]uint8{}
+
+	for f > 1 {
+		n := b.nodes[f]
+		if n.low != 0 {
+			assignment[n.level] = 0
+			f = n.low
+		} else {
+			assignment[n.level] = 1
+			f = n.high
+		}
+	}
+
+	return assignment
+}
+
+func (c *Circuit) outputs(b *bdd, inputs []string) []int {
+	values := make([]int, len(c.Wires))
+	for level, wire := range inputs {
+		values[c.index[wire]] = b.variable(level)
+	}
+
+	for _, g := range c.gates {
+		values[g.output] = b.gate(g, values)
+	}
+
+	outputs := []int{}
+	for _, wire := range c.Z {
+		outputs = append(outputs, values[wire])
+	}
+
+	return outputs
+}
+
+// equivalentBDD builds both circuits' outputs in one diagram and compares
+// them node for node
+func (c *Circuit) equivalentBDD(other *Circuit, inputs []string) error {
+	b := newBDD(len(inputs))
+	ours, theirs := c.outputs(b, inputs), other.outputs(b, inputs)
+
+	for bit := range ours {
+		if ours[bit] == theirs[bit] {
+			continue
+		}
+
+		// any assignment where the outputs differ is a counterexample
+		witness := b.satisfy(b.ite(ours[bit], b.ite(theirs[bit], 0, 1), theirs[bit]))
+
+		assignment := map[string]uint8{}
+		for level, wire := range inputs {
+			assignment[wire] = witness[level]
+		}
+
+		simulated, otherSimulated := c.Simulate(assignment), other.Simulate(assignment)
+		output := c.Wires[c.Z[bit]]
+
+		return &Mismatch{Output: output, Inputs: assignment, A: simulated[output], B: otherSimulated[other.Wires[other.Z[bit]]]}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var operatorColors = map[string]string{
+	"AND":  "lightblue",
+	"OR":   "palegreen",
+	"XOR":  "gold",
+	"NOT":  "lightgray",
+	"NAND": "lightskyblue",
+	"NOR":  "darkseagreen",
+	"XNOR": "orange",
+	"MUX":  "plum",
+}
+
+// verilogPrimitives maps operators to gate primitives, output first and then
+// the inputs. MUX has no primitive, so it's written as an assign.
+var verilogPrimitives = map[string]string{
+	"AND":  "and",
+	"OR":   "or",
+	"XOR":  "xor",
+	"NOT":  "not",
+	"NAND": "nand",
+	"NOR":  "nor",
+	"XNOR": "xnor",
+}
+
+var verilogKeywords = strings.Fields(`always and assign begin buf bufif0 bufif1 case
+	casex casez cmos deassign default defparam disable edge else end endcase
+	endfunction endmodule endprimitive endspecify endtable endtask event for
+	force forever fork function highz0 highz1 if ifnone initial inout input
+	integer join large macromodule medium module nand negedge nmos nor not
+	notif0 notif1 or output parameter pmos posedge primitive pull0 pull1
+	pulldown pullup rcmos real realtime reg release repeat rnmos rpmos rtran
+	rtranif0 rtranif1 scalared small specify specparam strong0 strong1 supply0
+	supply1 table task time tran tranif0 tranif1 tri tri0 tri1 triand trior
+	trireg vectored wait wand weak0 weak1 while wire wor xnor xor`)
+
+// verilogName escapes wire names that happen to be keywords, so a wire called
+// "and" becomes the escaped identifier \and, which ends at a space
+func verilogName(wire string) string {
+	if slices.Contains(verilogKeywords, wire) {
+		return "\\" + wire + " "
+	}
+
+	return wire
+}
+
+// ports splits the wires into inputs (driven by nothing), outputs (z wires)
+// and the internal wires between them
+func (conns Connections) ports() ([]string, []string, []string) {
+	inputs, outputs, internal := []string{}, []string{}, []string{}
+
+	for _, wire := range conns.Keys() {
+		switch {
+		case conns[wire].operator == "":
+			inputs = append(inputs, wire)
+		case wire[0] == 'z':
+			outputs = append(outputs, wire)
+		default:
+			internal = append(internal, wire)
+		}
+	}
+
+	return inputs, outputs, internal
+}
+
+// WriteDOT writes the netlist as a Graphviz digraph with one node per gate,
+// named after its output wire. Gates are filled by operator, inputs and
+// outputs are lined up at the top and bottom, and suspect wires are outlined
+// in red.
+func (conns Connections) WriteDOT(w io.Writer, suspects []string) error {
+	out := bufio.NewWriter(w)
+	inputs, outputs, _ := conns.ports()
+
+	fmt.Fprintln(out, "digraph circuit {")
+	fmt.Fprintln(out, "  rankdir=TB;")
+	fmt.Fprintln(out, "  node [style=filled, fontname=monospace];")
+
+	fmt.Fprintln(out, "  { rank=source;")
+	for _, wire := range inputs {
+		fmt.Fprintf(out, "    %q [shape=box, fillcolor=white];\n", wire)
+	}
+	fmt.Fprintln(out, "  }")
+
+	fmt.Fprintln(out, "  { rank=sink;")
+	for _, wire := range outputs {
+		fmt.Fprintf(out, "    %q;\n", wire)
+	}
+	fmt.Fprintln(out, "  }")
+
+	for _, wire := range conns.Keys() {
+		conn := conns[wire]
+		if conn.operator == "" {
+			continue
+		}
+
+		attributes := fmt.Sprintf("label=\"%s\\n%s\", fillcolor=%s", wire, conn.operator, operatorColors[conn.operator])
+		if wire[0] == 'z' {
+			attributes += ", shape=doubleoctagon"
+		}
+		if slices.Contains(suspects, wire) {
+			attributes += ", color=red, penwidth=3"
+		}
+
+		fmt.Fprintf(out, "  %q [%s];\n", wire, attributes)
+		for _, operand := range conn.operands() {
+			fmt.Fprintf(out, "  %q -> %q;\n", operand, wire)
+		}
+	}
+
+	fmt.Fprintln(out, "}")
+
+	return out.Flush()
+}
+
+// WriteVerilog writes the netlist as a structural Verilog module, with one
+// gate primitive per gate, the undriven wires as inputs and the z wires as
+// outputs
+func (conns Connections) WriteVerilog(w io.Writer, module string) error {
+	out := bufio.NewWriter(w)
+	inputs, outputs, internal := conns.ports()
+
+	ports := []string{}
+	for _, wire := range inputs {
+		ports = append(ports, "    input  wire "+verilogName(wire))
+	}
+	for _, wire := range outputs {
+		ports = append(ports, "    output wire "+verilogName(wire))
+	}
+
+	fmt.Fprintf(out, "module %s (\n%s\n);\n", module, strings.Join(ports, ",\n"))
+
+	for _, wire := range internal {
+		fmt.Fprintf(out, "    wire %s;\n", verilogName(wire))
+	}
+	fmt.Fprintln(out)
+
+	for _, wire := range conns.Keys() {
+		conn := conns[wire]
+		if conn.operator == "" {
+			continue
+		}
+
+		names := []string{verilogName(wire)}
+		for _, operand := range conn.operands() {
+			names = append(names, verilogName(operand))
+		}
+
+		if conn.operator == "MUX" {
+			fmt.Fprintf(out, "    assign %s = %s ? %s : %s;\n", names[0], names[1], names[3], names[2])
+			continue
+		}
+
+		primitive, ok := verilogPrimitives[conn.operator]
+		if !ok {
+			return fmt.Errorf("gate %s: no Verilog primitive for %s", wire, conn.operator)
+		}
+
+		fmt.Fprintf(out, "    %s g_%s (%s);\n", primitive, wire, strings.Join(names, ", "))
+	}
+
+	fmt.Fprintln(out, "endmodule")
+
+	return out.Flush()
+}
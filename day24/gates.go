@@ -0,0 +1,32 @@
+package main
+
+// Operator is a kind of gate. Eval works on 64 lanes at once, so one call
+// settles the gate for 64 independent inputs. Gates with fewer than three
+// operands ignore the rest.
+type Operator struct {
+	Arity int
+	Eval  func(a, b, c uint64) uint64
+}
+
+// Operators is the gate library. MUX takes a select line first and picks its
+// second operand when it's 0 and its third when it's 1.
+var Operators = map[string]Operator{
+	"AND":  {2, func(a, b, _ uint64) uint64 { return a & b }},
+	"OR":   {2, func(a, b, _ uint64) uint64 { return a | b }},
+	"XOR":  {2, func(a, b, _ uint64) uint64 { return a ^ b }},
+	"NOT":  {1, func(a, _, _ uint64) uint64 { return ^a }},
+	"NAND": {2, func(a, b, _ uint64) uint64 { return ^(a & b) }},
+	"NOR":  {2, func(a, b, _ uint64) uint64 { return ^(a | b) }},
+	"XNOR": {2, func(a, b, _ uint64) uint64 { return ^(a ^ b) }},
+	"MUX":  {3, func(s, a, b uint64) uint64 { return a&^s | b&s }},
+}
+
+// operands lists the wires a gate reads, or nothing for an input wire
+func (conn Connection) operands() []string {
+	operator, ok := Operators[conn.operator]
+	if !ok {
+		return nil
+	}
+
+	return []string{conn.operand1, conn.operand2, conn.operand3}[:operator.Arity]
+}
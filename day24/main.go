@@ -2,7 +2,9 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strconv"
@@ -10,16 +12,34 @@ import (
 )
 
 func main() {
+	dot := flag.Bool("dot", false, "print the circuit as a Graphviz digraph, with suspect wires highlighted, and exit")
+	verilog := flag.Bool("verilog", false, "print the circuit as a structural Verilog module and exit")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
 
 	conns := readInput(fileName)
 
+	if *dot {
+		if err := conns.WriteDOT(os.Stdout, Suspects(conns)); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	if *verilog {
+		if err := conns.WriteVerilog(os.Stdout, "day24"); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
 	fmt.Printf("The z wires output %s\n", Part1(conns))
 	fmt.Printf("The swapped gates are %s\n", Part2(conns))
 }
@@ -32,88 +52,70 @@ func readInput(filePath string) Connections {
 	}
 	defer file.Close()
 
+	conns, err := parseConnections(file)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil
+	}
+
+	return conns
+}
+
+// parseConnections reads "x00: 1" input values and "a AND b -> z" gates. A
+// gate line that doesn't name a known operator with the right number of
+// operands is an error, rather than a wire silently left undriven.
+func parseConnections(r io.Reader) (Connections, error) {
 	conns := Connections{}
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
-	for scanner.Scan() {
+	for i := 1; scanner.Scan(); i++ {
 		line := scanner.Text()
 
 		if strings.Contains(line, ":") {
 			parts := strings.Split(line, ":")
-			value, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
+			value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value in %q", i, line)
+			}
 
 			conns[parts[0]] = Connection{value: uint8(value)}
 		}
 
 		if strings.Contains(line, "->") {
-			parts := strings.Split(line, " ")
-			conns[parts[4]] = Connection{
-				value:    255,
-				operator: parts[1],
-				operand1: parts[0],
-				operand2: parts[2],
+			parts := strings.Fields(line)
+			if len(parts) < 3 || parts[len(parts)-2] != "->" {
+				return nil, fmt.Errorf("line %d: expected \"<gate> -> <wire>\", got %q", i, line)
+			}
+			inputs, output := parts[:len(parts)-2], parts[len(parts)-1]
+
+			// two-input gates are written "a AND b", others "NOT a" and "MUX s a b"
+			conn := Connection{}
+			if operator, ok := Operators[inputs[0]]; ok && len(inputs)-1 == operator.Arity {
+				conn.operator = inputs[0]
+				operands := make([]string, 3)
+				copy(operands, inputs[1:])
+				conn.operand1, conn.operand2, conn.operand3 = operands[0], operands[1], operands[2]
+			} else if len(inputs) == 3 && Operators[inputs[1]].Arity == 2 {
+				conn.operator, conn.operand1, conn.operand2 = inputs[1], inputs[0], inputs[2]
+			} else {
+				return nil, fmt.Errorf("line %d: unknown gate %q", i, strings.Join(inputs, " "))
 			}
-		}
-	}
-
-	return conns
-}
-
-func Part1(conns Connections) string {
-	binaryDigits := ""
 
-	for _, k := range conns.Keys() {
-		if k[0] != "z"[0] {
-			continue
+			conns[output] = conn
 		}
-		newDigit := strconv.Itoa(int(SettleValue(&conns, k)))
-		binaryDigits = fmt.Sprintf("%s%s", newDigit, binaryDigits)
 	}
 
-	return strconv.Itoa(binToDec(binaryDigits))
+	return conns, scanner.Err()
 }
 
-func SettleValue(conns *Connections, wire string) uint8 {
-	value := (*conns)[wire].value
-	operator := (*conns)[wire].operator
-	operand1 := (*conns)[wire].operand1
-	operand2 := (*conns)[wire].operand2
-
-	operand1Val := (*conns)[operand1].value
-	operand2Val := (*conns)[operand2].value
-
-	if value != 255 {
-		return value
-	}
-
-	if operand1Val == 255 {
-		operand1Val = SettleValue(conns, operand1)
-	}
-
-	if operand2Val == 255 {
-		operand2Val = SettleValue(conns, operand2)
-	}
-
-	switch operator {
-	case "OR":
-		value = operand1Val | operand2Val
-	case "XOR":
-		value = operand1Val ^ operand2Val
-	case "AND":
-		value = operand1Val & operand2Val
+func Part1(conns Connections) string {
+	circuit, err := NewCircuit(conns)
+	if err != nil {
+		return err.Error()
 	}
 
-	tmp := (*conns)[wire]
-	tmp.value = value
-	(*conns)[wire] = tmp
-
-	return value
-}
-
-func binToDec(binary string) int {
-	num, _ := strconv.ParseInt(binary, 2, 64)
-	return int(num)
+	return circuit.EvaluateBig(circuit.InitialXY()).String()
 }
 
 type Connection struct {
@@ -121,6 +123,7 @@ type Connection struct {
 	operator string
 	operand1 string
 	operand2 string
+	operand3 string
 }
 
 type Connections map[string]Connection
@@ -153,6 +156,23 @@ func (conns Connections) Find(operand1 string, operand2 string, operator string)
 }
 
 func Part2(conns Connections) string {
+	swaps, err := RepairAdder(conns, 4)
+	if err != nil {
+		return err.Error()
+	}
+
+	wires := []string{}
+	for _, swap := range swaps {
+		wires = append(wires, swap[0], swap[1])
+	}
+
+	sort.Strings(wires)
+	return strings.Join(wires, ",")
+}
+
+// Suspects walks the ripple-carry pattern bit by bit and returns the wires
+// that don't fit it. It's fast, but only a guess: RepairAdder checks its work.
+func Suspects(conns Connections) []string {
 	bits := 0
 	for i := range conns {
 		if i[0] == "x"[0] {
@@ -225,5 +245,5 @@ func Part2(conns Connections) string {
 	}
 
 	sort.Strings(oops)
-	return strings.Join(oops, ",")
+	return oops
 }
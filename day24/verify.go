@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// AdderError is an input the circuit doesn't add correctly
+type AdderError struct {
+	X    uint64
+	Y    uint64
+	Want uint64
+	Got  uint64
+}
+
+func (e *AdderError) Error() string {
+	return fmt.Sprintf("%d + %d: expected %d, got %d (wrong bits %b)", e.X, e.Y, e.Want, e.Got, e.Want^e.Got)
+}
+
+// adderWidth checks the circuit has the buses of an n-bit adder: n bits of x
+// and y in, n+1 bits of z out
+func (c *Circuit) adderWidth() (int, error) {
+	n := len(c.X)
+
+	switch {
+	case n == 0:
+		return 0, fmt.Errorf("circuit has no x inputs")
+	case len(c.Y) != n || len(c.Z) != n+1:
+		return 0, fmt.Errorf("expected %d y and %d z wires for a %d bit adder, got %d and %d", n, n+1, n, len(c.Y), len(c.Z))
+	case n > 63:
+		return 0, fmt.Errorf("%d bit adders are too wide to verify", n)
+	}
+
+	return n, nil
+}
+
+// checkSums compares the bits of x+y selected by each case's mask, and
+// returns the index of the first case that's wrong, or -1. Cases are
+// evaluated a lane's worth at a time, so an early failure stops it quickly.
+func (c *Circuit) checkSums(xs, ys, masks []uint64) (int, error) {
+	for start := 0; start < len(xs); start += Lanes {
+		end := min(start+Lanes, len(xs))
+
+		for i, got := range c.EvaluateMany(xs[start:end], ys[start:end]) {
+			i += start
+			if want := xs[i] + ys[i]; want&masks[i] != got&masks[i] {
+				return i, &AdderError{X: xs[i], Y: ys[i], Want: want, Got: got}
+			}
+		}
+	}
+
+	return -1, nil
+}
+
+// appendBitCases exercises the full adder for bit k with every combination of
+// x_k, y_k and a carry in, which the lower bits generate by adding 1 to all
+// ones
+func appendBitCases(xs, ys []uint64, k int) ([]uint64, []uint64) {
+	ones := uint64(1)<<k - 1
+
+	for _, carry := range []uint64{0, 1} {
+		if k == 0 && carry == 1 {
+			continue
+		}
+
+		for _, a := range []uint64{0, 1} {
+			for _, b := range []uint64{0, 1} {
+				xs = append(xs, a<<k|carry*ones)
+				ys = append(ys, b<<k|carry)
+			}
+		}
+	}
+
+	return xs, ys
+}
+
+// FirstBadBit returns the lowest bit whose sum or carry out is wrong for some
+// input that only uses the bits up to it, or the width if every bit is right
+func (c *Circuit) FirstBadBit() int {
+	n, err := c.adderWidth()
+	if err != nil {
+		return 0
+	}
+
+	xs, ys := make([]uint64, 0, 8*n), make([]uint64, 0, 8*n)
+	masks, bits := make([]uint64, 0, 8*n), make([]int, 0, 8*n)
+
+	for k := range n {
+		xs, ys = appendBitCases(xs, ys, k)
+
+		for len(masks) < len(xs) {
+			masks = append(masks, uint64(1)<<(k+2)-1)
+			bits = append(bits, k)
+		}
+	}
+
+	if i, _ := c.checkSums(xs, ys, masks); i >= 0 {
+		return bits[i]
+	}
+
+	return n
+}
+
+// VerifyAdder checks that z = x + y. It tries every bit on its own, the full
+// adder truth table at each bit, edge cases like all ones, and then random
+// pairs. The first failure is returned as an *AdderError.
+func (c *Circuit) VerifyAdder(trials int, random *rand.Rand) error {
+	n, err := c.adderWidth()
+	if err != nil {
+		return err
+	}
+
+	all := uint64(1)<<n - 1
+	alternating := uint64(0x5555555555555555)
+
+	xs := []uint64{0, all, 0, all, all, 1, alternating & all, ^alternating & all}
+	ys := []uint64{0, 0, all, all, 1, all, ^alternating & all, ^alternating & all}
+
+	for k := range n {
+		xs = append(xs, 1<<k, 0)
+		ys = append(ys, 0, 1<<k)
+		xs, ys = appendBitCases(xs, ys, k)
+	}
+
+	for range trials {
+		xs = append(xs, random.Uint64()&all)
+		ys = append(ys, random.Uint64()&all)
+	}
+
+	masks := make([]uint64, len(xs))
+	for i := range masks {
+		masks[i] = uint64(1)<<(n+1) - 1
+	}
+
+	_, err = c.checkSums(xs, ys, masks)
+
+	return err
+}
+
+// Cone returns every wire the given wires depend on, including themselves
+func (c *Circuit) Cone(wires ...string) map[string]bool {
+	drivers := map[string]Gate{}
+	for _, g := range c.Gates {
+		drivers[g.Output] = g
+	}
+
+	cone := map[string]bool{}
+	queue := slices.Clone(wires)
+
+	for len(queue) > 0 {
+		wire := queue[0]
+		queue = queue[1:]
+
+		if cone[wire] {
+			continue
+		}
+		cone[wire] = true
+
+		if g, ok := drivers[wire]; ok {
+			queue = append(queue, g.Operands...)
+		}
+	}
+
+	return cone
+}
+
+// Swap exchanges the outputs of two gates
+type Swap [2]string
+
+// RepairAdder searches for at most maxSwaps output swaps that turn the
+// netlist into a working adder. It fixes the lowest broken bit first: one
+// side of each swap must be a gate that first appears in the cones of the
+// broken bit's outputs, and a swap that breaks a lower bit is dropped. A bit
+// can need more than one swap before it adds, so a swap that leaves the
+// lowest broken bit where it was is allowed too, as long as its other side is
+// in the cones up to two outputs higher, where the rest of the bit's wiring
+// can end up. The search first tries repairs without any of those, then with
+// one, and so on, which keeps the common case fast. The result is confirmed
+// with VerifyAdder before it is returned.
+func RepairAdder(conns Connections, maxSwaps int) ([]Swap, error) {
+	circuit, err := NewCircuit(conns)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := circuit.adderWidth()
+	if err != nil {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewPCG(24, 2024))
+	outputs := []string{}
+	for _, g := range circuit.Gates {
+		outputs = append(outputs, g.Output)
+	}
+	slices.Sort(outputs)
+
+	// the same swaps made in a different order give the same circuit
+	var tried map[string]bool
+
+	// slack is how many more swaps may leave the lowest broken bit alone
+	var search func(circuit *Circuit, swaps []Swap, slack int) []Swap
+	search = func(circuit *Circuit, swaps []Swap, slack int) []Swap {
+		key := slices.Clone(swaps)
+		slices.SortFunc(key, func(a, b Swap) int { return strings.Compare(a[0]+","+a[1], b[0]+","+b[1]) })
+		if tried[fmt.Sprint(key, slack)] {
+			return nil
+		}
+		tried[fmt.Sprint(key, slack)] = true
+
+		bad := circuit.FirstBadBit()
+
+		if bad == n {
+			if circuit.VerifyAdder(1000, random) == nil {
+				return swaps
+			}
+			return nil
+		}
+
+		if len(swaps) == maxSwaps {
+			return nil
+		}
+
+		cone := circuit.Cone(fmt.Sprintf("z%02d", bad), fmt.Sprintf("z%02d", bad+1))
+		near := circuit.Cone(fmt.Sprintf("z%02d", bad), fmt.Sprintf("z%02d", bad+1), fmt.Sprintf("z%02d", min(bad+2, n)))
+		if bad > 0 {
+			for wire := range circuit.Cone(fmt.Sprintf("z%02d", bad-1)) {
+				delete(cone, wire)
+				delete(near, wire)
+			}
+		}
+
+		type candidate struct {
+			circuit *Circuit
+			swap    Swap
+		}
+		sameBit := []candidate{}
+
+		for _, a := range outputs {
+			if !cone[a] {
+				continue
+			}
+
+			for _, b := range outputs {
+				if a == b || (cone[b] && b < a) {
+					continue
+				}
+
+				next, err := circuit.Swap(a, b)
+				if err != nil {
+					continue
+				}
+
+				swap := Swap{min(a, b), max(a, b)}
+				switch nextBad := next.FirstBadBit(); {
+				case nextBad > bad:
+					if result := search(next, append(slices.Clone(swaps), swap), slack); result != nil {
+						return result
+					}
+				case nextBad == bad && near[b] && slack > 0 && len(swaps)+1 < maxSwaps:
+					sameBit = append(sameBit, candidate{next, swap})
+				}
+			}
+		}
+
+		for _, c := range sameBit {
+			if result := search(c.circuit, append(slices.Clone(swaps), c.swap), slack-1); result != nil {
+				return result
+			}
+		}
+
+		return nil
+	}
+
+	for slack := range max(maxSwaps, 1) {
+		tried = map[string]bool{}
+		if swaps := search(circuit, []Swap{}, slack); swaps != nil {
+			return swaps, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %d swaps or fewer repair the adder", maxSwaps)
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
+
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -28,53 +29,40 @@ func readInput(filePath string) ([][]int, [][]int) {
 	}
 	defer file.Close()
 
+	blocks, err := input.Blocks(file)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v", filePath, err)
+		return nil, nil
+	}
+
 	locks := [][]int{}
 	keys := [][]int{}
 
-	scanner := bufio.NewScanner(file)
-
-	pintype := ""
-	var pins []int
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if line == "" {
-			switch pintype {
-			case "lock":
-				locks = append(locks, pins)
-			case "key":
-				keys = append(keys, pins)
-			}
-
-			pintype = ""
-			continue
-		}
+	for _, block := range blocks {
+		var pins []int
 
-		if pintype == "" && line == "....." {
-			pintype = "key"
-			pins = []int{-1, -1, -1, -1, -1}
-			continue
-		}
-
-		if pintype == "" && line == "#####" {
-			pintype = "lock"
+		switch block[0] {
+		case "#####":
 			pins = []int{0, 0, 0, 0, 0}
+		case ".....":
+			pins = []int{-1, -1, -1, -1, -1}
+		default:
 			continue
 		}
 
-		for i, c := range line {
-			if c == '#' {
-				pins[i]++
+		for _, line := range block[1:] {
+			for i, c := range line {
+				if c == '#' {
+					pins[i]++
+				}
 			}
 		}
-	}
 
-	// Commit one more time since the input doesn't end in a blank line
-	switch pintype {
-	case "lock":
-		locks = append(locks, pins)
-	case "key":
-		keys = append(keys, pins)
+		if block[0] == "#####" {
+			locks = append(locks, pins)
+		} else {
+			keys = append(keys, pins)
+		}
 	}
 
 	return locks, keys
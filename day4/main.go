@@ -47,25 +47,21 @@ func PartOne(wordSearch shared.Grid) int {
 }
 
 func PartTwo(wordSearch shared.Grid) int {
-	matchPatterns := []shared.Grid{
-		{{"M", ".", "M"}, {".", "A", "."}, {"S", ".", "S"}},
-		{{"M", ".", "S"}, {".", "A", "."}, {"M", ".", "S"}},
-		{{"S", ".", "S"}, {".", "A", "."}, {"M", ".", "M"}},
-		{{"S", ".", "M"}, {".", "A", "."}, {"S", ".", "M"}},
-	}
+	pattern := shared.Grid{{"M", ".", "M"}, {".", "A", "."}, {"S", ".", "S"}}
 
+	// the four rotations cover every way the two MASes can cross
 	xMatches := 0
-	for _, matchPattern := range matchPatterns {
-		xMatches += getXMatches(matchPattern, wordSearch)
+	for turns := range 4 {
+		xMatches += getXMatches(pattern.View(shared.Symmetry{Turns: turns}), wordSearch)
 	}
 
 	return xMatches
 }
 
 // returns the number of times a version of an X-MAS appears in the charSlice
-func getXMatches(matchPattern shared.Grid, charSlice shared.Grid) int {
-	matchWidth := len(matchPattern[0])
-	matchHeight := len(matchPattern)
+func getXMatches(matchPattern shared.View, charSlice shared.Grid) int {
+	matchWidth := matchPattern.Width()
+	matchHeight := matchPattern.Height()
 
 	searchWidth := len(charSlice[0])
 	searchHeight := len(charSlice)
@@ -74,11 +70,7 @@ func getXMatches(matchPattern shared.Grid, charSlice shared.Grid) int {
 
 	for x := 0; x < searchWidth-matchWidth+1; x++ {
 		for y := 0; y < searchHeight-matchHeight+1; y++ {
-			subSlice := make(shared.Grid, matchHeight)
-
-			for row := 0; row < matchHeight; row++ {
-				subSlice[row] = charSlice[y+row][x : x+matchWidth]
-			}
+			subSlice := charSlice.SubGrid(shared.Coord{X: x, Y: y}, matchWidth, matchHeight)
 
 			if isMatch(matchPattern, subSlice) {
 				matches++
@@ -91,14 +83,16 @@ func getXMatches(matchPattern shared.Grid, charSlice shared.Grid) int {
 
 // returns true if the matchPattern is a match for charSlice, assumes that
 // matchPattern and charSlice are the same size
-func isMatch(matchPattern shared.Grid, charSlice shared.Grid) bool {
-	for x := 0; x < len(matchPattern[0]); x++ {
-		for y := 0; y < len(matchPattern); y++ {
-			if matchPattern[y][x] == "." {
+func isMatch(matchPattern shared.View, charSlice shared.View) bool {
+	for x := 0; x < matchPattern.Width(); x++ {
+		for y := 0; y < matchPattern.Height(); y++ {
+			loc := shared.Coord{X: x, Y: y}
+
+			if matchPattern.At(loc) == "." {
 				continue
 			}
 
-			if matchPattern[y][x] != charSlice[y][x] {
+			if matchPattern.At(loc) != charSlice.At(loc) {
 				return false
 			}
 		}
@@ -1,12 +1,11 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"slices"
-	"strconv"
-	"strings"
+
+	"github.com/too-gee/advent-of-code-2024/shared/input"
 )
 
 func main() {
@@ -121,41 +120,34 @@ func readInput(filePath string) ([][]int, [][]int) {
 	}
 	defer file.Close()
 
+	blocks, err := input.Blocks(file)
+	if err != nil || len(blocks) != 2 {
+		fmt.Println("Error reading file:", err)
+		return nil, nil
+	}
+
 	var rules [][]int
 	var updates [][]int
 
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// parse a rule
-		if strings.Contains(line, "|") {
-			strParts := strings.Split(line, "|")
-			rule := []int{}
-
-			for _, strPart := range strParts {
-				var part int
-				part, _ = strconv.Atoi(strPart)
-				rule = append(rule, part)
-			}
-
-			rules = append(rules, rule)
+	// the first block holds the rules, the second holds the updates
+	for _, line := range blocks[0] {
+		rule, err := input.Ints(line)
+		if err != nil {
+			fmt.Println("Error parsing rule:", err)
+			return nil, nil
 		}
 
-		// parse an update
-		if strings.Contains(line, ",") {
-			strParts := strings.Split(line, ",")
-			update := []int{}
-
-			for _, strPart := range strParts {
-				var part int
-				part, _ = strconv.Atoi(strPart)
-				update = append(update, part)
-			}
+		rules = append(rules, rule)
+	}
 
-			updates = append(updates, update)
+	for _, line := range blocks[1] {
+		update, err := input.Ints(line)
+		if err != nil {
+			fmt.Println("Error parsing update:", err)
+			return nil, nil
 		}
+
+		updates = append(updates, update)
 	}
 
 	return rules, updates
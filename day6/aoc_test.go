@@ -1,6 +1,14 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/gridtest"
+)
 
 type testCase struct {
 	fileName string
@@ -12,6 +20,7 @@ func TestAll(t *testing.T) {
 	cases := []testCase{
 		{"input_small.txt", PartOne, 41},
 		{"input_small.txt", PartTwo, 6},
+		{"input_small.txt", PartTwoBruteForce, 6},
 		{"input.txt", PartOne, 5534},
 		{"input.txt", PartTwo, 2262},
 	}
@@ -24,3 +33,162 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestSimulator(t *testing.T) {
+	lab := readInput("input_small.txt")
+
+	steps, loop := NewSimulator(lab).Run()
+	if loop != nil {
+		t.Fatalf("expected the guard to leave, got a loop %+v", loop)
+	}
+
+	turns := 0
+	for _, step := range steps {
+		if step.Turned {
+			turns++
+		}
+	}
+	if len(steps) != 54 || turns != 10 {
+		t.Errorf("expected 54 steps with 10 turns, got %d with %d", len(steps), turns)
+	}
+
+	expected := []string{
+		"....#.....",
+		"....hdddd#",
+		"....h...b.",
+		"..#.h...b.",
+		"..hdldd#b.",
+		"..h.h.b.b.",
+		".#aaiacab.",
+		".hddddfd#.",
+		"#aaaaabb..",
+		"......#b..",
+	}
+	traced := lab.trace(steps)
+	for y, row := range expected {
+		if got := strings.Join(traced.Grid[y], ""); got != row {
+			t.Errorf("trace row %d: expected %s, got %s", y, row, got)
+		}
+	}
+	if cells := charToDirections(traced.Grid[6][4]); !slices.Equal(cells, []string{"N", "W"}) {
+		t.Errorf("expected the crossing at 4,6 to be walked N and W, got %v", cells)
+	}
+
+	// the README's first obstruction sends the guard round the lab forever
+	blocked := lab.copy()
+	blocked.Grid[6][3] = "O"
+
+	simulator := NewSimulator(blocked)
+	steps, loop = simulator.Run()
+	if loop == nil || simulator.Loop() != loop {
+		t.Fatal("expected a loop")
+	}
+
+	// the guard is in the loop's entry state before its last Length steps
+	// and after them, and in each state once in between
+	states := []Entity{blocked.guard}
+	for _, step := range steps {
+		states = append(states, step.Entity)
+	}
+	cycle := states[len(states)-1-loop.Length:]
+
+	if cycle[0] != loop.Entry || cycle[len(cycle)-1] != loop.Entry {
+		t.Errorf("expected %+v to start and close the loop", loop.Entry)
+	}
+	if slices.Index(states, loop.Entry) != len(states)-1-loop.Length {
+		t.Errorf("expected the loop to be entered once")
+	}
+
+	for _, state := range cycle {
+		if !slices.Contains(loop.Cells, state.Coord) {
+			t.Errorf("%v is walked in the loop but missing from its cells", state.Coord)
+		}
+	}
+
+	// stopping early leaves the loop unknown
+	simulator = NewSimulator(blocked)
+	for range simulator.Steps() {
+		break
+	}
+	if simulator.Loop() != nil {
+		t.Error("expected no loop after one step")
+	}
+}
+
+func TestJumps(t *testing.T) {
+	lab := readInput("input_small.txt")
+	table := newJumps(lab)
+	seen := make([]int, lab.Width()*lab.Height()*4)
+
+	for y := range lab.Height() {
+		for x := range lab.Width() {
+			if lab.isObstructed(x, y) || lab.guard.Coord == (shared.Coord{X: x, Y: y}) {
+				continue
+			}
+
+			blocked := lab.copy()
+			blocked.Grid[y][x] = "O"
+			_, loop := NewSimulator(blocked).Run()
+
+			obstacle := shared.Coord{X: x, Y: y}
+			if got := table.loops(lab.guard, obstacle, seen, table.index(x, y)+1); got != (loop != nil) {
+				t.Errorf("obstruction at %v: expected loop %v, got %v", obstacle, loop != nil, got)
+			}
+		}
+	}
+}
+
+func BenchmarkPartTwo(b *testing.B) {
+	benchmarks := []struct {
+		name     string
+		function func(area) int
+	}{
+		{"jumps", PartTwo},
+		{"brute-force", PartTwoBruteForce},
+	}
+
+	for _, fileName := range []string{"input_small.txt", "input.txt"} {
+		lab := readInput(fileName)
+
+		for _, bench := range benchmarks {
+			b.Run(fileName+"/"+bench.name, func(b *testing.B) {
+				for range b.N {
+					bench.function(lab)
+				}
+			})
+		}
+	}
+}
+
+func TestBoards(t *testing.T) {
+	lab := readInput("input_small.txt")
+	steps, _ := NewSimulator(lab).Run()
+
+	// the README shows the guard just before each of their first three turns
+	// and as they leave
+	patrol := []gridtest.Step{}
+	for i := 0; i < len(steps)-1 && len(patrol) < 3; i++ {
+		if steps[i+1].Turned {
+			patrol = append(patrol, gridtest.Step{Label: fmt.Sprintf("Before turn %d", len(patrol)+1), Grid: lab.guardBoard(steps[i].Entity)})
+		}
+	}
+	patrol = append(patrol,
+		gridtest.Step{Label: "Leaving", Grid: lab.guardBoard(steps[len(steps)-1].Entity)},
+		gridtest.Step{Label: "Visited", Grid: lab.visitedBoard(steps)},
+	)
+	gridtest.GoldenSteps(t, "golden_patrol.txt", patrol)
+
+	loops := []gridtest.Step{}
+	for i, obstruction := range []shared.Coord{{X: 3, Y: 6}, {X: 6, Y: 7}, {X: 7, Y: 7}, {X: 1, Y: 8}, {X: 3, Y: 8}, {X: 7, Y: 9}} {
+		blocked := lab.copy()
+		blocked.Grid[obstruction.Y][obstruction.X] = "O"
+
+		steps, loop := NewSimulator(blocked).Run()
+		if loop == nil {
+			t.Errorf("option %d: expected an obstruction at %v to trap the guard", i+1, obstruction)
+		}
+
+		loops = append(loops, gridtest.Step{Label: fmt.Sprintf("Option %d", i+1), Grid: blocked.routeBoard(steps)})
+	}
+	gridtest.GoldenSteps(t, "golden_loops.txt", loops)
+}
@@ -0,0 +1,115 @@
+package main
+
+import "github.com/too-gee/advent-of-code-2024/shared"
+
+// jumps is a table of where the guard stops walking straight from every cell
+// in every direction: the last cell before an obstruction, or -1 if they walk
+// out of the lab. With it the guard's route is one lookup per turn.
+type jumps struct {
+	width  int
+	height int
+	next   [4][]int
+}
+
+func newJumps(lab area) jumps {
+	j := jumps{width: lab.Width(), height: lab.Height()}
+	for dir := range j.next {
+		j.next[dir] = make([]int, j.width*j.height)
+	}
+
+	// sweep each row and column from the side the guard is walking towards,
+	// carrying the cell they'd stop on
+	for x := range j.width {
+		stop := -1
+		for y := range j.height {
+			if lab.isObstructed(x, y) {
+				stop = j.index(x, y+1)
+			}
+			j.next[0][j.index(x, y)] = stop
+		}
+
+		stop = -1
+		for y := j.height - 1; y >= 0; y-- {
+			if lab.isObstructed(x, y) {
+				stop = j.index(x, y-1)
+			}
+			j.next[2][j.index(x, y)] = stop
+		}
+	}
+
+	for y := range j.height {
+		stop := -1
+		for x := j.width - 1; x >= 0; x-- {
+			if lab.isObstructed(x, y) {
+				stop = j.index(x-1, y)
+			}
+			j.next[1][j.index(x, y)] = stop
+		}
+
+		stop = -1
+		for x := range j.width {
+			if lab.isObstructed(x, y) {
+				stop = j.index(x+1, y)
+			}
+			j.next[3][j.index(x, y)] = stop
+		}
+	}
+
+	return j
+}
+
+func (j jumps) index(x int, y int) int {
+	return y*j.width + x
+}
+
+// walk is where the guard stops walking dir from cell when there's also an
+// obstruction at obstacle, or -1 if they leave
+func (j jumps) walk(cell int, dir int, obstacle shared.Coord) int {
+	stop := j.next[dir][cell]
+	x, y := cell%j.width, cell/j.width
+	stopX, stopY := stop%j.width, stop/j.width
+
+	// the new obstruction only matters if it's ahead and nearer than the stop
+	switch dir {
+	case 0:
+		if obstacle.X == x && obstacle.Y < y && (stop < 0 || obstacle.Y >= stopY) {
+			return j.index(x, obstacle.Y+1)
+		}
+	case 1:
+		if obstacle.Y == y && obstacle.X > x && (stop < 0 || obstacle.X <= stopX) {
+			return j.index(obstacle.X-1, y)
+		}
+	case 2:
+		if obstacle.X == x && obstacle.Y > y && (stop < 0 || obstacle.Y <= stopY) {
+			return j.index(x, obstacle.Y-1)
+		}
+	case 3:
+		if obstacle.Y == y && obstacle.X < x && (stop < 0 || obstacle.X >= stopX) {
+			return j.index(obstacle.X+1, y)
+		}
+	}
+
+	return stop
+}
+
+// loops follows the guard from start, one jump per turn, and reports whether
+// an obstruction at obstacle traps them. seen is scratch space with a slot for
+// every cell and direction. Slots holding stamp count as visited, so the same
+// slice can be reused by passing a new stamp each time.
+func (j jumps) loops(start Entity, obstacle shared.Coord, seen []int, stamp int) bool {
+	cell, dir := j.index(start.X, start.Y), directionIndex(start.direction)
+
+	for {
+		cell = j.walk(cell, dir, obstacle)
+		if cell < 0 {
+			return false
+		}
+
+		dir = (dir + 1) % 4
+
+		if seen[cell*4+dir] == stamp {
+			return true
+		}
+		seen[cell*4+dir] = stamp
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"slices"
@@ -12,16 +13,32 @@ import (
 )
 
 func main() {
+	trace := flag.Bool("trace", false, "draw the guard's route, report any loop and exit")
+	flag.Parse()
+
 	var fileName string
 
-	if len(os.Args) == 2 {
-		fileName = os.Args[1]
+	if flag.NArg() == 1 {
+		fileName = flag.Arg(0)
 	} else {
 		fileName = "input.txt"
 	}
 
 	lab := readInput(fileName)
 
+	if *trace {
+		steps, loop := NewSimulator(lab).Run()
+		traced := lab.trace(steps)
+		traced.draw()
+
+		if loop != nil {
+			fmt.Printf("The guard loops from %v facing %s, every %d steps over %d cells\n", loop.Entry.Coord, loop.Entry.direction, loop.Length, len(loop.Cells))
+		} else {
+			fmt.Printf("The guard leaves after %d steps\n", len(steps))
+		}
+		return
+	}
+
 	// part 1
 	visitedLocations := PartOne(lab)
 
@@ -34,27 +51,44 @@ func main() {
 }
 
 func PartOne(input area) int {
-	lab := input.copy()
+	steps, _ := NewSimulator(input).Run()
 
-	for {
-		nextLocation := lab.guard.nextLocation()
+	lab := input.trace(steps)
 
-		if !lab.Contains(nextLocation) {
-			break
-		}
+	return lab.visitedLocationCount()
+}
 
-		if lab.isObstructed(nextLocation.X, nextLocation.Y) {
-			lab.guard.turn()
-		} else {
-			lab.guard.move()
-			lab.markVisited()
+// PartTwo only tries obstructions on the guard's route, since they'd never
+// meet any other, and starts each try where the guard first walks up to the
+// obstruction, since the route before then doesn't change
+func PartTwo(input area) int {
+	steps, _ := NewSimulator(input).Run()
+	table := newJumps(input)
+	seen := make([]int, input.Width()*input.Height()*4)
+
+	tried := map[shared.Coord]bool{input.guard.Coord: true}
+	waysToLoop := 0
+	guard := input.guard
+
+	for _, step := range steps {
+		if !step.Turned && !tried[step.Coord] {
+			tried[step.Coord] = true
+
+			if table.loops(guard, step.Coord, seen, len(tried)) {
+				waysToLoop += 1
+			}
 		}
+
+		guard = step.Entity
 	}
 
-	return lab.visitedLocationCount()
+	return waysToLoop
 }
 
-func PartTwo(input area) int {
+// PartTwoBruteForce is PartTwo before the jump tables, kept as the baseline
+// for BenchmarkPartTwo. It tries an obstruction on every open cell and walks
+// the guard from the start each time, marking the grid as they go.
+func PartTwoBruteForce(input area) int {
 	waysToLoop := 0
 
 	for x := 0; x < len(input.Grid[0]); x++ {
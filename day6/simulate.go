@@ -0,0 +1,214 @@
+package main
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+// Step is one thing the guard does: either turn right where they stand or
+// move forward a cell. Entity is where they are and which way they face after.
+type Step struct {
+	Entity
+	Turned bool
+}
+
+// Loop is the cycle a guard who never leaves ends up walking. Entry is the
+// first position and direction that repeats, Length is how many steps it takes
+// to get back to it and Cells are the cells walked on the way, in order.
+type Loop struct {
+	Entry  Entity
+	Length int
+	Cells  []shared.Coord
+}
+
+// Simulator walks the guard through the lab without marking it up
+type Simulator struct {
+	lab   area
+	guard Entity
+	loop  *Loop
+}
+
+func NewSimulator(lab area) *Simulator {
+	return &Simulator{lab: lab, guard: lab.guard}
+}
+
+// Steps yields each step until the guard leaves the lab or repeats a position
+// and direction, in which case the step that closes the loop is the last one
+// and Loop reports it
+func (s *Simulator) Steps() iter.Seq[Step] {
+	return func(yield func(Step) bool) {
+		width := s.lab.Width()
+
+		// seen holds each state's index in states plus 1, or 0 if it's new
+		seen := make([]int, width*s.lab.Height()*4)
+		state := func(e Entity) int { return (e.Y*width+e.X)*4 + directionIndex(e.direction) }
+
+		states := []Entity{s.guard}
+		seen[state(s.guard)] = 1
+
+		for {
+			next := s.guard.nextLocation()
+			if !s.lab.Contains(next) {
+				return
+			}
+
+			step := Step{}
+			if s.lab.isObstructed(next.X, next.Y) {
+				s.guard.turn()
+				step.Turned = true
+			} else {
+				s.guard.move()
+			}
+			step.Entity = s.guard
+
+			if first := seen[state(s.guard)]; first > 0 {
+				s.loop = newLoop(states[first-1:])
+				yield(step)
+				return
+			}
+
+			seen[state(s.guard)] = len(states) + 1
+			states = append(states, s.guard)
+
+			if !yield(step) {
+				return
+			}
+		}
+	}
+}
+
+func newLoop(states []Entity) *Loop {
+	loop := &Loop{Entry: states[0], Length: len(states)}
+	walked := map[shared.Coord]bool{}
+
+	for _, e := range states {
+		if !walked[e.Coord] {
+			walked[e.Coord] = true
+			loop.Cells = append(loop.Cells, e.Coord)
+		}
+	}
+
+	return loop
+}
+
+// Loop is the loop the guard got stuck in, or nil if they haven't
+func (s *Simulator) Loop() *Loop {
+	return s.loop
+}
+
+// Run walks the guard until they leave or loop
+func (s *Simulator) Run() ([]Step, *Loop) {
+	steps := slices.Collect(s.Steps())
+	return steps, s.loop
+}
+
+func directionIndex(direction string) int {
+	switch direction {
+	case "N":
+		return 0
+	case "E":
+		return 1
+	case "S":
+		return 2
+	case "W":
+		return 3
+	}
+
+	panic("Invalid Entity direction")
+}
+
+// trace copies the lab and marks every cell the guard walked with the
+// directions they walked it in, the way charToDirections reads them, leaving
+// the guard where the last step put them
+func (m area) trace(steps []Step) area {
+	lab := m.copy()
+
+	for _, step := range steps {
+		lab.guard = step.Entity
+		if !step.Turned {
+			lab.markVisited()
+		}
+	}
+
+	return lab
+}
+
+// board is the lab with nothing walked, the way the README draws it
+func (m area) board() shared.Grid {
+	board := m.copy().Grid
+
+	for y, row := range board {
+		for x, cell := range row {
+			if cell != "#" && cell != "O" {
+				board[y][x] = "."
+			}
+		}
+	}
+
+	return board
+}
+
+// guardBoard draws the guard on the empty lab
+func (m area) guardBoard(guard Entity) shared.Grid {
+	board := m.board()
+	board[guard.Y][guard.X] = guard.draw()
+
+	return board
+}
+
+// visitedBoard marks every cell the guard stood on with an X
+func (m area) visitedBoard(steps []Step) shared.Grid {
+	board := m.board()
+	traced := m.trace(steps)
+
+	for y, row := range traced.Grid {
+		for x, cell := range row {
+			if cell != "." && cell != "#" && cell != "O" {
+				board[y][x] = "X"
+			}
+		}
+	}
+
+	return board
+}
+
+// routeBoard draws the route the way the README's part two does: | where the
+// guard walks up or down, - where they walk left or right, + where they do
+// both, and the guard where they started
+func (m area) routeBoard(steps []Step) shared.Grid {
+	board := m.board()
+
+	// turns count too, so the corners get both directions
+	lab := m.copy()
+	for _, step := range steps {
+		lab.guard = step.Entity
+		lab.markVisited()
+	}
+
+	for y, row := range lab.Grid {
+		for x, cell := range row {
+			if cell == "." || cell == "#" || cell == "O" {
+				continue
+			}
+
+			directions := charToDirections(cell)
+			vertical := slices.Contains(directions, "N") || slices.Contains(directions, "S")
+			horizontal := slices.Contains(directions, "E") || slices.Contains(directions, "W")
+
+			switch {
+			case vertical && horizontal:
+				board[y][x] = "+"
+			case vertical:
+				board[y][x] = "|"
+			default:
+				board[y][x] = "-"
+			}
+		}
+	}
+
+	board[m.guard.Y][m.guard.X] = m.guard.draw()
+
+	return board
+}
@@ -33,7 +33,7 @@ func main() {
 }
 
 func PartOne(grid shared.Grid) int {
-	antiNodeLocations := coordList{}
+	antiNodeLocations := shared.NewSparseGrid()
 
 	for _, antennas := range getAntennaLocations(grid) {
 		pairs := pairs(makeRange(0, len(antennas)-1))
@@ -44,17 +44,17 @@ func PartOne(grid shared.Grid) int {
 
 			antinode := shared.Coord{X: (2 * antennaA.X) - antennaB.X, Y: (2 * antennaA.Y) - antennaB.Y}
 
-			if grid.Contains(antinode) && !slices.Contains(antiNodeLocations, antinode) {
-				antiNodeLocations = append(antiNodeLocations, antinode)
+			if grid.Contains(antinode) {
+				antiNodeLocations.Set(antinode, "#")
 			}
 		}
 	}
 
-	return len(antiNodeLocations)
+	return antiNodeLocations.Len()
 }
 
 func PartTwo(grid shared.Grid) int {
-	antiNodeLocations := coordList{}
+	antiNodeLocations := shared.NewSparseGrid()
 
 	for _, antennas := range getAntennaLocations(grid) {
 		pairs := pairs(makeRange(0, len(antennas)-1))
@@ -63,9 +63,7 @@ func PartTwo(grid shared.Grid) int {
 			antennaA := antennas[pair[0]]
 			antennaB := antennas[pair[1]]
 
-			if !slices.Contains(antiNodeLocations, antennaA) {
-				antiNodeLocations = append(antiNodeLocations, antennaA)
-			}
+			antiNodeLocations.Set(antennaA, "#")
 
 			rise := antennaA.Y - antennaB.Y
 			run := antennaA.X - antennaB.X
@@ -79,9 +77,7 @@ func PartTwo(grid shared.Grid) int {
 					break
 				}
 
-				if !slices.Contains(antiNodeLocations, antinode) {
-					antiNodeLocations = append(antiNodeLocations, antinode)
-				}
+				antiNodeLocations.Set(antinode, "#")
 
 				currentX += run
 				currentY += rise
@@ -90,7 +86,7 @@ func PartTwo(grid shared.Grid) int {
 		}
 	}
 
-	return len(antiNodeLocations)
+	return antiNodeLocations.Len()
 }
 
 func getAntennaLocations(grid shared.Grid) map[string]coordList {
@@ -0,0 +1,134 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CellDiff is a cell whose value differs between two grids. A cell that only
+// exists in one of the grids reads as "" in the other.
+type CellDiff struct {
+	Loc    Coord
+	Before string
+	After  string
+}
+
+// Equal reports whether g and other have the same size and the same cells
+func (g Grid) Equal(other Grid) bool {
+	if g.Height() != other.Height() {
+		return false
+	}
+
+	for y := range g {
+		if len(g[y]) != len(other[y]) {
+			return false
+		}
+
+		for x := range g[y] {
+			if g[y][x] != other[y][x] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Diff returns every cell that changes going from g to after, in reading order
+func (g Grid) Diff(after Grid) []CellDiff {
+	diffs := []CellDiff{}
+
+	for y := range max(len(g), len(after)) {
+		width := 0
+		if y < len(g) {
+			width = len(g[y])
+		}
+		if y < len(after) {
+			width = max(width, len(after[y]))
+		}
+
+		for x := range width {
+			before, now := cellAt(g, x, y), cellAt(after, x, y)
+
+			if before != now {
+				diffs = append(diffs, CellDiff{Loc: Coord{X: x, Y: y}, Before: before, After: now})
+			}
+		}
+	}
+
+	return diffs
+}
+
+// cellAt is like At but tolerates ragged rows
+func cellAt(g Grid, x int, y int) string {
+	if y < 0 || y >= len(g) || x < 0 || x >= len(g[y]) {
+		return ""
+	}
+
+	return g[y][x]
+}
+
+// String returns the grid as lines of text
+func (g Grid) String() string {
+	var sb strings.Builder
+
+	for _, row := range g {
+		sb.WriteString(strings.Join(row, ""))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// DiffString lays want and got out side by side, followed by a third column
+// with an X on every cell that differs
+func DiffString(want Grid, got Grid) string {
+	height := max(len(want), len(got))
+	width := func(g Grid) int {
+		w := 0
+		for _, row := range g {
+			w = max(w, len(row))
+		}
+		return w
+	}
+	wantWidth, gotWidth := width(want), width(got)
+	markWidth := max(wantWidth, gotWidth)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%-*s   %-*s   %s\n", wantWidth, "want", gotWidth, "got", "diff")
+
+	for y := range height {
+		wantRow, gotRow, markRow := "", "", ""
+
+		for x := range markWidth {
+			wantCell, gotCell := cellAt(want, x, y), cellAt(got, x, y)
+
+			if x < wantWidth {
+				wantRow += padCell(wantCell)
+			}
+			if x < gotWidth {
+				gotRow += padCell(gotCell)
+			}
+
+			if wantCell != gotCell {
+				markRow += "X"
+			} else {
+				markRow += "."
+			}
+		}
+
+		fmt.Fprintf(&sb, "%s   %s   %s\n", wantRow, gotRow, markRow)
+	}
+
+	return sb.String()
+}
+
+// padCell keeps missing cells from shifting the columns
+func padCell(cell string) string {
+	if cell == "" {
+		return " "
+	}
+
+	return cell
+}
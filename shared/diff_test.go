@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := Grid{{"#", ".", "."}, {".", "@", "."}}
+	after := Grid{{"#", ".", "."}, {".", ".", "@"}, {"#"}}
+
+	if !before.Equal(Grid{{"#", ".", "."}, {".", "@", "."}}) || before.Equal(after) {
+		t.Errorf("unexpected Equal results")
+	}
+
+	expected := []CellDiff{
+		{Loc: Coord{X: 1, Y: 1}, Before: "@", After: "."},
+		{Loc: Coord{X: 2, Y: 1}, Before: ".", After: "@"},
+		{Loc: Coord{X: 0, Y: 2}, Before: "", After: "#"},
+	}
+
+	if diffs := before.Diff(after); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %v, got %v", expected, diffs)
+	}
+
+	printed := DiffString(before, after)
+	for _, line := range []string{"#..   #..   ...", ".@.   ..@   .XX", "      #     X.."} {
+		if !strings.Contains(printed, line+"\n") {
+			t.Errorf("expected %q in\n%s", line, printed)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package shared
 
 import (
 	"fmt"
-	"math"
 	"slices"
 )
 
@@ -48,28 +47,51 @@ func (g Grid) Draw(markers map[string]string, paths map[string][]Coord) {
 		return
 	}
 
-	if markers == nil {
-		markers = map[string]string{}
-	}
-
-	if paths == nil {
-		paths = map[string][]Coord{}
-	}
+	Draw(g, markers, paths)
+}
 
+// Extent returns the corners of the smallest box holding every non-"." cell
+func (g Grid) Extent() (Coord, Coord) {
 	yMin, yMax := g.Height()-1, 0
 	xMin, xMax := g.Width()-1, 0
 
 	for y := range g.Height() {
 		for x := range g.Width() {
 			if g[y][x] != "." {
-				yMin = int(math.Min(float64(yMin), float64(y)))
-				yMax = int(math.Max(float64(yMax), float64(y)))
-				xMin = int(math.Min(float64(xMin), float64(x)))
-				xMax = int(math.Max(float64(xMax), float64(x)))
+				yMin = min(yMin, y)
+				yMax = max(yMax, y)
+				xMin = min(xMin, x)
+				xMax = max(xMax, x)
 			}
 		}
 	}
 
+	return Coord{X: xMin, Y: yMin}, Coord{X: xMax, Y: yMax}
+}
+
+// Drawable is anything that Draw can render
+type Drawable interface {
+	At(loc Coord) string
+	Contains(loc CoordLike) bool
+	Extent() (Coord, Coord)
+}
+
+// Draw prints the part of d that holds something other than ".", with a
+// border around it. Cells on a path are printed with the path's key and
+// cells whose value is a key of markers are printed with the marker.
+func Draw(d Drawable, markers map[string]string, paths map[string][]Coord) {
+	if markers == nil {
+		markers = map[string]string{}
+	}
+
+	if paths == nil {
+		paths = map[string][]Coord{}
+	}
+
+	low, high := d.Extent()
+	yMin, yMax := low.Y, high.Y
+	xMin, xMax := low.X, high.X
+
 	for y := yMin - 2; y <= yMax+2; y++ {
 		for x := xMin - 2; x <= xMax+2; x++ {
 			loc := Coord{X: x, Y: y}
@@ -93,7 +115,7 @@ func (g Grid) Draw(markers map[string]string, paths map[string][]Coord) {
 			}
 
 			// print the grid
-			if g.Contains(loc) {
+			if d.Contains(loc) {
 				pathPrint := false
 				for cell, path := range paths {
 					if slices.Contains(path, loc) {
@@ -105,20 +127,21 @@ func (g Grid) Draw(markers map[string]string, paths map[string][]Coord) {
 					continue
 				}
 
-				cell, ok := markers[g[y][x]]
+				value := d.At(loc)
+				cell, ok := markers[value]
 
 				if ok {
 					fmt.Print(cell)
 					continue
 				}
 
-				switch g[y][x] {
+				switch value {
 				case "#":
 					fmt.Print("⬛")
 				case ".":
 					fmt.Print("　")
 				default:
-					fmt.Print(g[y][x])
+					fmt.Print(value)
 				}
 			}
 		}
@@ -138,6 +161,34 @@ func (g Grid) Neighbors(loc Coord, blockers []string) map[string]Coord {
 	return neighbors
 }
 
+// Distances is the fewest steps from start to every cell it can reach without
+// crossing a blocker, found with a breadth-first search
+func (g Grid) Distances(start Coord, blockers []string) map[Coord]int {
+	distances := map[Coord]int{}
+	if !g.Contains(start) || slices.Contains(blockers, g.At(start)) {
+		return distances
+	}
+
+	distances[start] = 0
+	queue := []Coord{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, neighbor := range g.Neighbors(current, blockers) {
+			if _, ok := distances[neighbor]; ok {
+				continue
+			}
+
+			distances[neighbor] = distances[current] + 1
+			queue = append(queue, neighbor)
+		}
+	}
+
+	return distances
+}
+
 func (g Grid) At(loc Coord) string {
 	if g.Contains(loc) {
 		return g[loc.Y][loc.X]
@@ -146,24 +197,25 @@ func (g Grid) At(loc Coord) string {
 	return ""
 }
 
-func (g *Grid) Rotate(dir string) {
-	// rotate the grid
-	width, height := (*g).Width(), (*g).Height()
-	result := MakeGrid(width, height)
+// Rotate turns the grid clockwise by a quarter turn for each of turns.
+// Negative values turn counter-clockwise.
+func (g *Grid) Rotate(turns int) {
+	(*g) = (*g).View(Symmetry{Turns: turns}).ToGrid()
+}
 
-	for y := 0; y < width; y++ {
-		for x := 0; x < height; x++ {
-			if dir == "L" {
-				result[height-1-x][y] = (*g)[y][x]
-			}
+// Transpose mirrors the grid across its top left to bottom right diagonal
+func (g *Grid) Transpose() {
+	(*g) = (*g).View(MainDiagonal).ToGrid()
+}
 
-			if dir == "R" {
-				result[x][height-1-y] = (*g)[y][x]
-			}
-		}
-	}
+// FlipH mirrors the grid left to right
+func (g *Grid) FlipH() {
+	(*g) = (*g).View(MirrorH).ToGrid()
+}
 
-	(*g) = result
+// FlipV mirrors the grid top to bottom
+func (g *Grid) FlipV() {
+	(*g) = (*g).View(MirrorV).ToGrid()
 }
 
 func MakeGrid(width int, height int) Grid {
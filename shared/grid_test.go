@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDistances(t *testing.T) {
+	grid := Grid{}
+	for _, row := range []string{
+		"..#.",
+		".##.",
+		"....",
+		"#.#.",
+	} {
+		grid = append(grid, strings.Split(row, ""))
+	}
+
+	expected := map[Coord]int{
+		{X: 0, Y: 0}: 0, {X: 1, Y: 0}: 1, {X: 3, Y: 0}: 7,
+		{X: 0, Y: 1}: 1, {X: 3, Y: 1}: 6,
+		{X: 0, Y: 2}: 2, {X: 1, Y: 2}: 3, {X: 2, Y: 2}: 4, {X: 3, Y: 2}: 5,
+		{X: 1, Y: 3}: 4, {X: 3, Y: 3}: 6,
+	}
+
+	if distances := grid.Distances(Coord{X: 0, Y: 0}, []string{"#"}); !reflect.DeepEqual(distances, expected) {
+		t.Errorf("expected %v, got %v", expected, distances)
+	}
+
+	if distances := grid.Distances(Coord{X: 2, Y: 0}, []string{"#"}); len(distances) != 0 {
+		t.Errorf("starting on a blocker: expected nothing, got %v", distances)
+	}
+
+	if distances := grid.Distances(Coord{X: 9, Y: 9}, []string{"#"}); len(distances) != 0 {
+		t.Errorf("starting outside the grid: expected nothing, got %v", distances)
+	}
+}
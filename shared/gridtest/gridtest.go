@@ -0,0 +1,132 @@
+// Package gridtest compares grids against golden text files in tests.
+package gridtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+	"github.com/too-gee/advent-of-code-2024/shared/input"
+)
+
+var update = flag.Bool("update", false, "rewrite golden grid files with the current results")
+
+// Golden fails t if got doesn't match the grid stored in the text file at
+// path, printing a side by side diff. Run the tests with -update to write got
+// to the file instead.
+func Golden(t testing.TB, path string, got shared.Grid) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got.String()), 0o644); err != nil {
+			t.Fatalf("updating %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := Read(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	if !want.Equal(got) {
+		t.Errorf("%s: %d cells differ\n%s", path, len(want.Diff(got)), shared.DiffString(want, got))
+	}
+}
+
+// Read loads a grid from a text file with one row per line
+func Read(path string) (shared.Grid, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := input.Lines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return input.Grid(lines)
+}
+
+// Step is one labelled board in a sequence, like the "Move <:" boards in the
+// puzzle READMEs
+type Step struct {
+	Label string
+	Grid  shared.Grid
+}
+
+// GoldenSteps fails t unless got matches the boards stored in the text file at
+// path, in order. Boards are separated by blank lines and each starts with its
+// label followed by a colon, so the READMEs' walkthroughs can be pasted in as
+// they are. Run the tests with -update to write got to the file instead.
+func GoldenSteps(t testing.TB, path string, got []Step) {
+	t.Helper()
+
+	if *update {
+		boards := []string{}
+		for _, step := range got {
+			boards = append(boards, step.Label+":\n"+step.Grid.String())
+		}
+
+		if err := os.WriteFile(path, []byte(strings.Join(boards, "\n")), 0o644); err != nil {
+			t.Fatalf("updating %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := ReadSteps(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	if len(want) != len(got) {
+		t.Errorf("%s: expected %d boards, got %d", path, len(want), len(got))
+	}
+
+	for i := range min(len(want), len(got)) {
+		if want[i].Label != got[i].Label {
+			t.Errorf("%s: board %d: expected label %q, got %q", path, i+1, want[i].Label, got[i].Label)
+		}
+
+		if !want[i].Grid.Equal(got[i].Grid) {
+			t.Errorf("%s: board %d (%s): %d cells differ\n%s", path, i+1, want[i].Label, len(want[i].Grid.Diff(got[i].Grid)), shared.DiffString(want[i].Grid, got[i].Grid))
+		}
+	}
+}
+
+// ReadSteps loads the labelled boards GoldenSteps compares against
+func ReadSteps(path string) ([]Step, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocks, err := input.Blocks(file)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []Step{}
+
+	for i, block := range blocks {
+		label, ok := strings.CutSuffix(block[0], ":")
+		if !ok {
+			return nil, fmt.Errorf("board %d: expected a label ending in a colon, got %q", i+1, block[0])
+		}
+
+		grid, err := input.Grid(block[1:])
+		if err != nil {
+			return nil, fmt.Errorf("board %d (%s): %w", i+1, label, err)
+		}
+
+		steps = append(steps, Step{Label: label, Grid: grid})
+	}
+
+	return steps, nil
+}
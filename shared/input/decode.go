@@ -0,0 +1,379 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DecodeError reports a line of input that could not be decoded.
+type DecodeError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("line %d: %q: %v", e.Line, e.Text, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decode reads r and fills dest, which must be a pointer to a struct or to a
+// slice of structs, using `input` struct tags. A tag is a template for one
+// line of input: literal text plus {Name} placeholders that capture a value
+// into the field called Name. Dotted paths reach into nested structs and {}
+// captures into the tagged field itself. For example:
+//
+//	type ClawMachine struct {
+//		ButtonA shared.Coord `input:"Button A: X+{X}, Y+{Y}"`
+//		ButtonB shared.Coord `input:"Button B: X+{X}, Y+{Y}"`
+//		Prize   shared.Coord `input:"Prize: X={X}, Y={Y}"`
+//	}
+//
+// Tagged fields are matched against the input lines in order, skipping blank
+// lines. A slice of structs with a template consumes every consecutive line that
+// matches it, one element per line. The ",block" option decodes a nested struct
+// from the next blank-line separated block and ",blocks" decodes a slice of
+// structs from all of the remaining blocks. Decoding into a pointer to a slice
+// of structs treats each block of the input as one element.
+func Decode(r io.Reader, dest any) error {
+	lines, err := Lines(r)
+	if err != nil {
+		return err
+	}
+
+	return DecodeLines(lines, dest)
+}
+
+// DecodeLines is Decode for input that has already been split into lines.
+func DecodeLines(lines []string, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", dest)
+	}
+
+	d := &decoder{}
+	for i, text := range lines {
+		d.lines = append(d.lines, numberedLine{number: i + 1, text: text})
+	}
+
+	target := v.Elem()
+
+	switch {
+	case target.Kind() == reflect.Struct:
+		return d.decodeStruct(target)
+	case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Struct:
+		return d.decodeBlocks(target)
+	}
+
+	return fmt.Errorf("decode target must point to a struct or a slice of structs, got %T", dest)
+}
+
+// DecodeLine matches line against pattern and stores each named capture group
+// in the field of dest with the same name. Underscores in group names separate
+// nested fields, so (?P<Pos_X>\d+) fills dest.Pos.X.
+func DecodeLine(line string, pattern *regexp.Regexp, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", dest)
+	}
+
+	paths := make([][]string, pattern.NumSubexp()+1)
+	for i, name := range pattern.SubexpNames() {
+		if name != "" {
+			paths[i] = strings.Split(name, "_")
+		}
+	}
+
+	err := fill(v.Elem(), &linePattern{re: pattern, paths: paths}, line)
+	if err != nil {
+		return &DecodeError{Line: 1, Text: line, Err: err}
+	}
+
+	return nil
+}
+
+type numberedLine struct {
+	number int
+	text   string
+}
+
+type decoder struct {
+	lines []numberedLine
+	pos   int
+}
+
+func (d *decoder) skipBlank() {
+	for d.pos < len(d.lines) && strings.TrimSpace(d.lines[d.pos].text) == "" {
+		d.pos++
+	}
+}
+
+// nextBlock returns the lines up to the next blank line and advances past them
+func (d *decoder) nextBlock() *decoder {
+	d.skipBlank()
+
+	start := d.pos
+	for d.pos < len(d.lines) && strings.TrimSpace(d.lines[d.pos].text) != "" {
+		d.pos++
+	}
+
+	return &decoder{lines: d.lines[start:d.pos]}
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	if d.pos < len(d.lines) {
+		line := d.lines[d.pos]
+		return &DecodeError{Line: line.number, Text: line.text, Err: fmt.Errorf(format, args...)}
+	}
+
+	return &DecodeError{Err: fmt.Errorf("unexpected end of input: "+format, args...)}
+}
+
+func (d *decoder) decodeStruct(v reflect.Value) error {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("input")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			return fmt.Errorf("field %s.%s is tagged but unexported", t.Name(), field.Name)
+		}
+
+		if err := d.decodeField(v.Field(i), field, tag); err != nil {
+			return err
+		}
+	}
+
+	d.skipBlank()
+	if d.pos < len(d.lines) {
+		return d.errorf("unexpected line after %s", t.Name())
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeField(v reflect.Value, field reflect.StructField, tag string) error {
+	isStructSlice := v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct
+
+	switch tag {
+	case ",block":
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("field %s: ,block requires a struct", field.Name)
+		}
+
+		d.skipBlank()
+		if d.pos >= len(d.lines) {
+			return d.errorf("missing block for %s", field.Name)
+		}
+
+		return d.nextBlock().decodeStruct(v)
+	case ",blocks":
+		if !isStructSlice {
+			return fmt.Errorf("field %s: ,blocks requires a slice of structs", field.Name)
+		}
+
+		return d.decodeBlocks(v)
+	}
+
+	pattern, err := compileTemplate(tag)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	// repeated lines, one element each
+	if isStructSlice {
+		d.skipBlank()
+
+		for d.pos < len(d.lines) && pattern.re.MatchString(d.lines[d.pos].text) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+
+			if err := fill(elem, pattern, d.lines[d.pos].text); err != nil {
+				return d.errorf("%s: %w", field.Name, err)
+			}
+
+			v.Set(reflect.Append(v, elem))
+			d.pos++
+		}
+
+		return nil
+	}
+
+	d.skipBlank()
+	if d.pos >= len(d.lines) {
+		return d.errorf("missing line for %s", field.Name)
+	}
+
+	if !pattern.re.MatchString(d.lines[d.pos].text) {
+		return d.errorf("does not match %s pattern %q", field.Name, tag)
+	}
+
+	if err := fill(v, pattern, d.lines[d.pos].text); err != nil {
+		return d.errorf("%s: %w", field.Name, err)
+	}
+
+	d.pos++
+
+	return nil
+}
+
+func (d *decoder) decodeBlocks(v reflect.Value) error {
+	for d.skipBlank(); d.pos < len(d.lines); d.skipBlank() {
+		elem := reflect.New(v.Type().Elem()).Elem()
+
+		if err := d.nextBlock().decodeStruct(elem); err != nil {
+			return err
+		}
+
+		v.Set(reflect.Append(v, elem))
+	}
+
+	return nil
+}
+
+type linePattern struct {
+	re *regexp.Regexp
+
+	// the field path for each capture group; empty means the target itself
+	// and nil means an unnamed group that's only there for grouping
+	paths [][]string
+}
+
+var templateCache sync.Map
+
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_.]*)\}`)
+
+// compileTemplate turns "p={Pos.X},{Pos.Y}" into an anchored regexp with one
+// capture group per placeholder
+func compileTemplate(template string) (*linePattern, error) {
+	if cached, ok := templateCache.Load(template); ok {
+		return cached.(*linePattern), nil
+	}
+
+	expr := "^"
+	paths := [][]string{nil}
+	last := 0
+
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		expr += regexp.QuoteMeta(template[last:loc[0]]) + "(.*?)"
+		last = loc[1]
+
+		name := template[loc[2]:loc[3]]
+		if name == "" {
+			paths = append(paths, []string{})
+		} else {
+			paths = append(paths, strings.Split(name, "."))
+		}
+	}
+
+	expr += regexp.QuoteMeta(template[last:]) + "$"
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := &linePattern{re: re, paths: paths}
+	templateCache.Store(template, pattern)
+
+	return pattern, nil
+}
+
+func fill(v reflect.Value, pattern *linePattern, line string) error {
+	match := pattern.re.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("does not match %q", pattern.re)
+	}
+
+	for i := 1; i < len(match); i++ {
+		// unnamed groups are only there for grouping
+		if pattern.paths[i] == nil {
+			continue
+		}
+
+		target := v
+
+		for _, name := range pattern.paths[i] {
+			if target.Kind() != reflect.Struct {
+				return fmt.Errorf("cannot find %s in %s", name, target.Type())
+			}
+
+			target = target.FieldByNameFunc(func(field string) bool {
+				return strings.EqualFold(field, name)
+			})
+
+			if !target.IsValid() || !target.CanSet() {
+				return fmt.Errorf("no settable field %q", strings.Join(pattern.paths[i], "."))
+			}
+		}
+
+		if err := setValue(target, match[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setValue(v reflect.Value, text string) error {
+	text = strings.TrimSpace(text)
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(strings.TrimPrefix(text, "+"), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(value)
+	case reflect.Slice:
+		parts := strings.Split(text, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+
+		for i, part := range parts {
+			if err := setValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+var intPattern = regexp.MustCompile(`[-+]?\d+`)
+
+// Lines reads every line from r, stripping the line endings (LF or CRLF).
+// A trailing newline does not produce an extra empty line.
+func Lines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	lines := []string{}
+
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// Blocks reads r and groups its lines into blocks separated by one or more
+// blank lines. The last block is returned whether or not the input ends in a
+// blank line.
+func Blocks(r io.Reader) ([][]string, error) {
+	lines, err := Lines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitBlocks(lines), nil
+}
+
+// SplitBlocks groups already-read lines into blocks separated by blank lines.
+func SplitBlocks(lines []string) [][]string {
+	blocks := [][]string{}
+	var block []string
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if block != nil {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			continue
+		}
+
+		block = append(block, line)
+	}
+
+	// commit the last block since the input may not end in a blank line
+	if block != nil {
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// Ints extracts every signed integer that appears in line, in order. A sign
+// straight after a digit is a separator rather than part of the number, so
+// "10-20" is 10 and 20.
+func Ints(line string) ([]int, error) {
+	ints := []int{}
+
+	for _, loc := range intPattern.FindAllStringIndex(line, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && line[start-1] >= '0' && line[start-1] <= '9' {
+			start++
+		}
+
+		match := line[start:end]
+		value, err := strconv.Atoi(match)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q in %q: %w", match, line, err)
+		}
+
+		ints = append(ints, value)
+	}
+
+	return ints, nil
+}
+
+// Grid builds a shared.Grid with one cell per character. Leading and trailing
+// blank lines are dropped, as is trailing whitespace on each row. Every
+// remaining row must have the same width.
+func Grid(lines []string) (shared.Grid, error) {
+	start, end := 0, len(lines)
+
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	grid := shared.Grid{}
+
+	for i := start; i < end; i++ {
+		row := strings.Split(strings.TrimRight(lines[i], " \t\r"), "")
+
+		if len(grid) > 0 && len(row) != len(grid[0]) {
+			return nil, fmt.Errorf("line %d: row has width %d, expected %d", i+1, len(row), len(grid[0]))
+		}
+
+		grid = append(grid, row)
+	}
+
+	return grid, nil
+}
+
+// Fields splits line on whitespace and parses each field into the matching
+// pointer in dest. Supported targets are *string, *int, *int64, *uint64,
+// *float64, *bool and *[]int (which consumes all remaining fields).
+func Fields(line string, dest ...any) error {
+	fields := strings.Fields(line)
+
+	for i, d := range dest {
+		if list, ok := d.(*[]int); ok {
+			if i != len(dest)-1 {
+				return fmt.Errorf("field %d of %q: *[]int must be the last target", i+1, line)
+			}
+
+			*list = []int{}
+
+			for _, field := range fields[min(i, len(fields)):] {
+				value, err := strconv.Atoi(field)
+				if err != nil {
+					return fmt.Errorf("field %d of %q: %w", i+1, line, err)
+				}
+
+				*list = append(*list, value)
+			}
+
+			return nil
+		}
+
+		if i >= len(fields) {
+			return fmt.Errorf("%q has %d fields, expected %d", line, len(fields), len(dest))
+		}
+
+		if err := scanValue(fields[i], d); err != nil {
+			return fmt.Errorf("field %d of %q: %w", i+1, line, err)
+		}
+	}
+
+	if len(fields) != len(dest) {
+		return fmt.Errorf("%q has %d fields, expected %d", line, len(fields), len(dest))
+	}
+
+	return nil
+}
+
+func scanValue(text string, dest any) error {
+	switch d := dest.(type) {
+	case *string:
+		*d = text
+	case *int:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return err
+		}
+		*d = value
+	case *int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		*d = value
+	case *uint64:
+		value, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		*d = value
+	case *float64:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		*d = value
+	case *bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		*d = value
+	default:
+		return fmt.Errorf("unsupported target type %s", reflect.TypeOf(dest))
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/too-gee/advent-of-code-2024/shared"
+)
+
+func TestLines(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"a\nb", []string{"a", "b"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\r\nb\r\n", []string{"a", "b"}},
+		{"a\n\nb\n\n", []string{"a", "", "b", ""}},
+	}
+
+	for _, c := range cases {
+		result, err := Lines(strings.NewReader(c.input))
+		if err != nil || !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%q: expected %q, got %q (%v)", c.input, c.expected, result, err)
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected [][]string
+	}{
+		{"", [][]string{}},
+		{"a\nb\n\nc", [][]string{{"a", "b"}, {"c"}}},
+		{"a\nb\n\nc\n", [][]string{{"a", "b"}, {"c"}}},
+		{"a\r\nb\r\n\r\nc\r\n\r\n", [][]string{{"a", "b"}, {"c"}}},
+		{"\n\na\n\n\n\nb\n\n", [][]string{{"a"}, {"b"}}},
+	}
+
+	for _, c := range cases {
+		result, err := Blocks(strings.NewReader(c.input))
+		if err != nil || !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%q: expected %q, got %q (%v)", c.input, c.expected, result, err)
+		}
+	}
+}
+
+func TestInts(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []int
+	}{
+		{"", []int{}},
+		{"p=0,4 v=3,-3", []int{0, 4, 3, -3}},
+		{"Button A: X+94, Y+34", []int{94, 34}},
+		{"75|13", []int{75, 13}},
+		{"10-20", []int{10, 20}},
+		{"1-3 a: abc", []int{1, 3}},
+		{"x=-5..-2, y=3+4", []int{-5, -2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		result, err := Ints(c.input)
+		if err != nil || !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%q: expected %v, got %v (%v)", c.input, c.expected, result, err)
+		}
+	}
+
+	if _, err := Ints("99999999999999999999999"); err == nil {
+		t.Errorf("expected an overflow error")
+	}
+}
+
+func TestGrid(t *testing.T) {
+	lines, _ := Lines(strings.NewReader("\r\n#.#\r\n.#. \r\n\r\n"))
+	grid, err := Grid(lines)
+	expected := shared.Grid{{"#", ".", "#"}, {".", "#", "."}}
+
+	if err != nil || !reflect.DeepEqual(grid, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, grid, err)
+	}
+
+	if _, err := Grid([]string{"###", "##", "###"}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a ragged row error on line 2, got %v", err)
+	}
+}
+
+func TestFields(t *testing.T) {
+	var name string
+	var count int
+	var big int64
+	var rest []int
+
+	if err := Fields("abc 12 -9000000000 1 2 3", &name, &count, &big, &rest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if name != "abc" || count != 12 || big != -9000000000 || !reflect.DeepEqual(rest, []int{1, 2, 3}) {
+		t.Errorf("got %q %d %d %v", name, count, big, rest)
+	}
+
+	if err := Fields("1 2", &count); err == nil {
+		t.Errorf("expected an error for extra fields")
+	}
+
+	if err := Fields("1", &count, &count); err == nil {
+		t.Errorf("expected an error for missing fields")
+	}
+
+	if err := Fields("x", &count); err == nil {
+		t.Errorf("expected a parse error")
+	}
+
+	// a misplaced *[]int is reported even when its fields wouldn't parse
+	err := Fields("x 1", &rest, &count)
+	if err == nil || !strings.Contains(err.Error(), "must be the last target") {
+		t.Errorf("expected a misplaced *[]int error, got %v", err)
+	}
+}
+
+type decodeMachine struct {
+	ButtonA shared.Coord `input:"Button A: X+{X}, Y+{Y}"`
+	ButtonB shared.Coord `input:"Button B: X+{X}, Y+{Y}"`
+	Prize   shared.Coord `input:"Prize: X={X}, Y={Y}"`
+}
+
+type decodeRobot struct {
+	Pos shared.Coord
+	Vel shared.Coord
+}
+
+type decodeComputer struct {
+	A       int64 `input:"Register A: {}"`
+	B       int64 `input:"Register B: {}"`
+	Program []int `input:"Program: {}"`
+}
+
+type decodeNested struct {
+	Name   string `input:"Name: {}"`
+	Robots struct {
+		List []decodeRobot `input:"p={Pos.X},{Pos.Y} v={Vel.X},{Vel.Y}"`
+	} `input:",block"`
+	Machines []decodeMachine `input:",blocks"`
+}
+
+func TestDecode(t *testing.T) {
+	var machines []decodeMachine
+	err := Decode(strings.NewReader("Button A: X+94, Y+34\r\nButton B: X+22, Y+67\r\nPrize: X=8400, Y=5400\r\n\r\nButton A: X+26, Y+66\nButton B: X+67, Y+21\nPrize: X=12748, Y=12176"), &machines)
+	expected := []decodeMachine{
+		{shared.Coord{X: 94, Y: 34}, shared.Coord{X: 22, Y: 67}, shared.Coord{X: 8400, Y: 5400}},
+		{shared.Coord{X: 26, Y: 66}, shared.Coord{X: 67, Y: 21}, shared.Coord{X: 12748, Y: 12176}},
+	}
+	if err != nil || !reflect.DeepEqual(machines, expected) {
+		t.Errorf("machines: expected %v, got %v (%v)", expected, machines, err)
+	}
+
+	var computer decodeComputer
+	err = Decode(strings.NewReader("Register A: 729\nRegister B: -1\n\nProgram: 0,1,5,4,3,0\n"), &computer)
+	if err != nil || computer.A != 729 || computer.B != -1 || !reflect.DeepEqual(computer.Program, []int{0, 1, 5, 4, 3, 0}) {
+		t.Errorf("computer: got %+v (%v)", computer, err)
+	}
+
+	var nested decodeNested
+	err = Decode(strings.NewReader("Name: test\n\np=0,4 v=3,-3\np=6,3 v=-1,-3\n\nButton A: X+1, Y+2\nButton B: X+3, Y+4\nPrize: X=5, Y=6\n"), &nested)
+	robots := []decodeRobot{{shared.Coord{X: 0, Y: 4}, shared.Coord{X: 3, Y: -3}}, {shared.Coord{X: 6, Y: 3}, shared.Coord{X: -1, Y: -3}}}
+	if err != nil || nested.Name != "test" || !reflect.DeepEqual(nested.Robots.List, robots) || len(nested.Machines) != 1 {
+		t.Errorf("nested: got %+v (%v)", nested, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		line  int
+	}{
+		{"Register A: 729\nRegister B: x\nProgram: 1", 2},
+		{"Register A: 729\nRegister C: 0\nProgram: 1", 2},
+		{"Register A: 729\nRegister B: 0\nProgram: 1,a", 3},
+		{"Register A: 729\nRegister B: 0\nProgram: 1\nextra", 4},
+	}
+
+	for _, c := range cases {
+		var computer decodeComputer
+		err := Decode(strings.NewReader(c.input), &computer)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != c.line {
+			t.Errorf("%q: expected an error on line %d, got %v", c.input, c.line, err)
+		}
+	}
+
+	var computer decodeComputer
+	if err := Decode(strings.NewReader("Register A: 729\n"), &computer); err == nil {
+		t.Errorf("expected an error for missing lines")
+	}
+}
+
+func TestDecodeLine(t *testing.T) {
+	var robot decodeRobot
+	pattern := regexp.MustCompile(`p=(?P<Pos_X>-?\d+),(?P<Pos_Y>-?\d+) v=(?P<Vel_X>-?\d+),(?P<Vel_Y>-?\d+)`)
+
+	err := DecodeLine("p=2,4 v=2,-3", pattern, &robot)
+	expected := decodeRobot{shared.Coord{X: 2, Y: 4}, shared.Coord{X: 2, Y: -3}}
+	if err != nil || robot != expected {
+		t.Errorf("expected %v, got %v (%v)", expected, robot, err)
+	}
+
+	if err := DecodeLine("q=2,4", pattern, &robot); err == nil {
+		t.Errorf("expected a match error")
+	}
+
+	// unnamed groups are left alone
+	var count struct{ N int }
+	grouped := regexp.MustCompile(`(?:x|y)(a|b) (?P<N>\d+)`)
+	if err := DecodeLine("yb 42", grouped, &count); err != nil || count.N != 42 {
+		t.Errorf("expected N = 42, got %d (%v)", count.N, err)
+	}
+}
@@ -0,0 +1,156 @@
+package shared
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SparseGrid is a map-backed grid with no fixed size. Cells that have not been
+// set read as "." and coordinates may be negative.
+type SparseGrid struct {
+	cells map[Coord]string
+
+	// the extent is cached, grown by Set and recomputed after a Delete
+	low, high Coord
+	stale     bool
+}
+
+func NewSparseGrid() *SparseGrid {
+	return &SparseGrid{cells: map[Coord]string{}}
+}
+
+// SparseFromGrid copies every cell of g that isn't "." into a new SparseGrid
+func SparseFromGrid(g Grid) *SparseGrid {
+	sparse := NewSparseGrid()
+
+	for y := range g.Height() {
+		for x := range g.Width() {
+			sparse.Set(Coord{X: x, Y: y}, g[y][x])
+		}
+	}
+
+	return sparse
+}
+
+// Set stores value at loc. Setting a cell to "." removes it.
+func (s *SparseGrid) Set(loc Coord, value string) {
+	if value == "." {
+		s.Delete(loc)
+		return
+	}
+
+	if !s.stale {
+		if len(s.cells) == 0 {
+			s.low, s.high = loc, loc
+		} else {
+			s.low.X, s.low.Y = min(s.low.X, loc.X), min(s.low.Y, loc.Y)
+			s.high.X, s.high.Y = max(s.high.X, loc.X), max(s.high.Y, loc.Y)
+		}
+	}
+
+	s.cells[loc] = value
+}
+
+func (s *SparseGrid) Delete(loc Coord) {
+	if _, ok := s.cells[loc]; ok {
+		delete(s.cells, loc)
+		s.stale = true
+	}
+}
+
+func (s *SparseGrid) At(loc Coord) string {
+	if value, ok := s.cells[loc]; ok {
+		return value
+	}
+
+	return "."
+}
+
+func (s *SparseGrid) Has(loc Coord) bool {
+	_, ok := s.cells[loc]
+	return ok
+}
+
+// Len returns the number of cells that have been set
+func (s *SparseGrid) Len() int {
+	return len(s.cells)
+}
+
+// Coords returns the set cells in reading order
+func (s *SparseGrid) Coords() []Coord {
+	coords := make([]Coord, 0, len(s.cells))
+
+	for loc := range s.cells {
+		coords = append(coords, loc)
+	}
+
+	slices.SortFunc(coords, func(a, b Coord) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+
+	return coords
+}
+
+// Extent returns the corners of the smallest box holding every set cell. An
+// empty grid has an extent of a single cell at the origin.
+func (s *SparseGrid) Extent() (Coord, Coord) {
+	if len(s.cells) == 0 {
+		return Coord{}, Coord{}
+	}
+
+	if s.stale {
+		first := true
+
+		for loc := range s.cells {
+			if first {
+				s.low, s.high = loc, loc
+				first = false
+				continue
+			}
+
+			s.low.X, s.low.Y = min(s.low.X, loc.X), min(s.low.Y, loc.Y)
+			s.high.X, s.high.Y = max(s.high.X, loc.X), max(s.high.Y, loc.Y)
+		}
+
+		s.stale = false
+	}
+
+	return s.low, s.high
+}
+
+// Contains reports whether loc falls within the current extent
+func (s *SparseGrid) Contains(loc CoordLike) bool {
+	low, high := s.Extent()
+
+	return loc.GetX() >= low.X &&
+		loc.GetX() <= high.X &&
+		loc.GetY() >= low.Y &&
+		loc.GetY() <= high.Y
+}
+
+func (s *SparseGrid) Width() int {
+	low, high := s.Extent()
+	return high.X - low.X + 1
+}
+
+func (s *SparseGrid) Height() int {
+	low, high := s.Extent()
+	return high.Y - low.Y + 1
+}
+
+// ToGrid copies the extent of s into a dense Grid. The returned Coord is the
+// position in s of the dense grid's top left cell.
+func (s *SparseGrid) ToGrid() (Grid, Coord) {
+	low, high := s.Extent()
+	grid := MakeGrid(high.X-low.X+1, high.Y-low.Y+1)
+
+	for loc, value := range s.cells {
+		grid[loc.Y-low.Y][loc.X-low.X] = value
+	}
+
+	return grid, low
+}
+
+func (s *SparseGrid) Draw(markers map[string]string, paths map[string][]Coord) {
+	Draw(s, markers, paths)
+}
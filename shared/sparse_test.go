@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSparseGrid(t *testing.T) {
+	sparse := NewSparseGrid()
+	sparse.Set(Coord{X: -2, Y: 1}, "#")
+	sparse.Set(Coord{X: 3, Y: -1}, "A")
+	sparse.Set(Coord{X: 0, Y: 0}, ".")
+
+	low, high := sparse.Extent()
+	if low != (Coord{X: -2, Y: -1}) || high != (Coord{X: 3, Y: 1}) {
+		t.Errorf("expected extent (-2,-1)-(3,1), got %v-%v", low, high)
+	}
+
+	if sparse.Len() != 2 || sparse.Width() != 6 || sparse.Height() != 3 {
+		t.Errorf("expected 2 cells in 6x3, got %d cells in %dx%d", sparse.Len(), sparse.Width(), sparse.Height())
+	}
+
+	if sparse.At(Coord{X: 100, Y: -100}) != "." || !sparse.Contains(Coord{X: 0, Y: 0}) || sparse.Contains(Coord{X: 4, Y: 0}) {
+		t.Errorf("unexpected At/Contains results")
+	}
+
+	grid, origin := sparse.ToGrid()
+	expected := Grid{
+		{".", ".", ".", ".", ".", "A"},
+		{".", ".", ".", ".", ".", "."},
+		{"#", ".", ".", ".", ".", "."},
+	}
+	if origin != low || !reflect.DeepEqual(grid, expected) {
+		t.Errorf("expected %v at %v, got %v at %v", expected, low, grid, origin)
+	}
+
+	roundTrip := SparseFromGrid(grid)
+	if !reflect.DeepEqual(roundTrip.Coords(), []Coord{{X: 5, Y: 0}, {X: 0, Y: 2}}) {
+		t.Errorf("unexpected coords %v", roundTrip.Coords())
+	}
+
+	// the extent follows cells being deleted and added again
+	sparse.Delete(Coord{X: 3, Y: -1})
+	if low, high := sparse.Extent(); low != (Coord{X: -2, Y: 1}) || high != (Coord{X: -2, Y: 1}) {
+		t.Errorf("after a delete, expected extent (-2,1)-(-2,1), got %v-%v", low, high)
+	}
+
+	sparse.Set(Coord{X: -2, Y: 1}, ".")
+	sparse.Set(Coord{X: 7, Y: 8}, "B")
+	sparse.Set(Coord{X: 9, Y: 5}, "C")
+	if low, high := sparse.Extent(); low != (Coord{X: 7, Y: 5}) || high != (Coord{X: 9, Y: 8}) {
+		t.Errorf("after emptying and refilling, expected extent (7,5)-(9,8), got %v-%v", low, high)
+	}
+}
@@ -0,0 +1,176 @@
+package shared
+
+// Symmetry is one of the eight ways to map a rectangle onto itself: an
+// optional left/right flip followed by Turns clockwise quarter turns.
+type Symmetry struct {
+	Turns int
+	Flip  bool
+}
+
+var (
+	Identity      = Symmetry{}
+	RotateRight   = Symmetry{Turns: 1}
+	Rotate180     = Symmetry{Turns: 2}
+	RotateLeft    = Symmetry{Turns: 3}
+	MirrorH       = Symmetry{Flip: true}
+	MirrorV       = Symmetry{Turns: 2, Flip: true}
+	MainDiagonal  = Symmetry{Turns: 3, Flip: true}
+	AntiDiagonal  = Symmetry{Turns: 1, Flip: true}
+	AllSymmetries = []Symmetry{
+		Identity, RotateRight, Rotate180, RotateLeft,
+		MirrorH, MirrorV, MainDiagonal, AntiDiagonal,
+	}
+)
+
+func (s Symmetry) normalized() Symmetry {
+	return Symmetry{Turns: ((s.Turns % 4) + 4) % 4, Flip: s.Flip}
+}
+
+// Then returns the symmetry that applies s followed by next
+func (s Symmetry) Then(next Symmetry) Symmetry {
+	// a flip reverses the direction of any turns made before it
+	turns := s.Turns
+	if next.Flip {
+		turns = -turns
+	}
+
+	return Symmetry{Turns: turns + next.Turns, Flip: s.Flip != next.Flip}.normalized()
+}
+
+// Inverse returns the symmetry that undoes s
+func (s Symmetry) Inverse() Symmetry {
+	// reflections undo themselves
+	if s.Flip {
+		return s.normalized()
+	}
+
+	return Symmetry{Turns: -s.Turns}.normalized()
+}
+
+// View is a read-only window onto a Grid, seen through a Symmetry. It shares
+// its cells with the underlying grid, so changes to the grid show through.
+type View struct {
+	grid     Grid
+	origin   Coord
+	width    int
+	height   int
+	symmetry Symmetry
+}
+
+// View returns a view of the whole grid with s applied
+func (g Grid) View(s Symmetry) View {
+	return View{grid: g, width: g.Width(), height: g.Height(), symmetry: s.normalized()}
+}
+
+// SubGrid returns a width x height view whose top left cell is origin
+func (g Grid) SubGrid(origin Coord, width int, height int) View {
+	return View{grid: g, origin: origin, width: width, height: height}
+}
+
+func (v View) Width() int {
+	if v.symmetry.Turns%2 == 1 {
+		return v.height
+	}
+
+	return v.width
+}
+
+func (v View) Height() int {
+	if v.symmetry.Turns%2 == 1 {
+		return v.width
+	}
+
+	return v.height
+}
+
+func (v View) Contains(loc CoordLike) bool {
+	return loc.GetX() >= 0 &&
+		loc.GetX() < v.Width() &&
+		loc.GetY() >= 0 &&
+		loc.GetY() < v.Height()
+}
+
+// source maps a location in the view back to the underlying grid
+func (v View) source(loc Coord) Coord {
+	width, height := v.Width(), v.Height()
+
+	// undo each clockwise turn
+	for range v.symmetry.Turns {
+		loc = Coord{X: loc.Y, Y: width - 1 - loc.X}
+		width, height = height, width
+	}
+
+	if v.symmetry.Flip {
+		loc.X = width - 1 - loc.X
+	}
+
+	return Coord{X: v.origin.X + loc.X, Y: v.origin.Y + loc.Y}
+}
+
+// At returns the cell at loc, or "" if loc is outside the view or the grid
+func (v View) At(loc Coord) string {
+	if !v.Contains(loc) {
+		return ""
+	}
+
+	return v.grid.At(v.source(loc))
+}
+
+// Extent returns the corners of the smallest box holding every non-"." cell
+// of the view, in view coordinates, the same as Grid.Extent
+func (v View) Extent() (Coord, Coord) {
+	yMin, yMax := v.Height()-1, 0
+	xMin, xMax := v.Width()-1, 0
+
+	for y := range v.Height() {
+		for x := range v.Width() {
+			if v.At(Coord{X: x, Y: y}) != "." {
+				yMin = min(yMin, y)
+				yMax = max(yMax, y)
+				xMin = min(xMin, x)
+				xMax = max(xMax, x)
+			}
+		}
+	}
+
+	return Coord{X: xMin, Y: yMin}, Coord{X: xMax, Y: yMax}
+}
+
+// Transform returns a view of v with s applied on top of its own symmetry
+func (v View) Transform(s Symmetry) View {
+	v.symmetry = v.symmetry.Then(s)
+	return v
+}
+
+// SubGrid returns a width x height window of v whose top left cell is origin
+func (v View) SubGrid(origin Coord, width int, height int) View {
+	a := v.source(origin)
+	b := v.source(Coord{X: origin.X + width - 1, Y: origin.Y + height - 1})
+
+	// symmetries map rectangles to rectangles, so the window is the box
+	// between its two corners in the underlying grid
+	return View{
+		grid:     v.grid,
+		origin:   Coord{X: min(a.X, b.X), Y: min(a.Y, b.Y)},
+		width:    max(a.X, b.X) - min(a.X, b.X) + 1,
+		height:   max(a.Y, b.Y) - min(a.Y, b.Y) + 1,
+		symmetry: v.symmetry,
+	}
+}
+
+// ToGrid copies the view into a new Grid
+func (v View) ToGrid() Grid {
+	result := MakeGrid(v.Width(), v.Height())
+
+	for y := range v.Height() {
+		for x := range v.Width() {
+			result[y][x] = v.At(Coord{X: x, Y: y})
+		}
+	}
+
+	return result
+}
+
+func (v View) Draw(markers map[string]string, paths map[string][]Coord) {
+	Draw(v, markers, paths)
+}
@@ -0,0 +1,146 @@
+package shared
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSymmetries(t *testing.T) {
+	// a 3x2 grid with every cell distinct, so each symmetry gives a different result
+	grid := Grid{
+		{"a", "b", "c"},
+		{"d", "e", "f"},
+	}
+
+	cases := []struct {
+		name     string
+		symmetry Symmetry
+		expected Grid
+	}{
+		{"identity", Identity, Grid{{"a", "b", "c"}, {"d", "e", "f"}}},
+		{"rotate right", RotateRight, Grid{{"d", "a"}, {"e", "b"}, {"f", "c"}}},
+		{"rotate 180", Rotate180, Grid{{"f", "e", "d"}, {"c", "b", "a"}}},
+		{"rotate left", RotateLeft, Grid{{"c", "f"}, {"b", "e"}, {"a", "d"}}},
+		{"mirror h", MirrorH, Grid{{"c", "b", "a"}, {"f", "e", "d"}}},
+		{"mirror v", MirrorV, Grid{{"d", "e", "f"}, {"a", "b", "c"}}},
+		{"main diagonal", MainDiagonal, Grid{{"a", "d"}, {"b", "e"}, {"c", "f"}}},
+		{"anti diagonal", AntiDiagonal, Grid{{"f", "c"}, {"e", "b"}, {"d", "a"}}},
+	}
+
+	seen := map[string]bool{}
+
+	for _, c := range cases {
+		result := grid.View(c.symmetry).ToGrid()
+		if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, result)
+		}
+
+		seen[fmt.Sprint(result)] = true
+
+		// applying one symmetry to the materialised result of another must
+		// match the composition table, and the inverse must undo it
+		for _, other := range AllSymmetries {
+			there := result.View(other).ToGrid()
+			back := there.View(other.Inverse()).ToGrid()
+
+			if !reflect.DeepEqual(back, result) {
+				t.Errorf("%s then %v: inverse did not round-trip", c.name, other)
+			}
+
+			if composed := grid.View(c.symmetry.Then(other)).ToGrid(); !reflect.DeepEqual(composed, there) {
+				t.Errorf("%s then %v: Then gives %v, applying them in turn gives %v", c.name, other, composed, there)
+			}
+		}
+	}
+
+	if len(seen) != 8 {
+		t.Errorf("expected 8 distinct symmetries, got %d", len(seen))
+	}
+}
+
+func TestGridTransforms(t *testing.T) {
+	grid := Grid{{"a", "b", "c"}, {"d", "e", "f"}}
+
+	rotated := grid.View(Identity).ToGrid()
+	rotated.Rotate(1)
+	if !reflect.DeepEqual(rotated, Grid{{"d", "a"}, {"e", "b"}, {"f", "c"}}) {
+		t.Errorf("Rotate(1): got %v", rotated)
+	}
+
+	rotated.Rotate(-1)
+	if !reflect.DeepEqual(rotated, grid) {
+		t.Errorf("Rotate(-1): got %v", rotated)
+	}
+
+	transposed := grid.View(Identity).ToGrid()
+	transposed.Transpose()
+	if !reflect.DeepEqual(transposed, Grid{{"a", "d"}, {"b", "e"}, {"c", "f"}}) {
+		t.Errorf("Transpose: got %v", transposed)
+	}
+
+	flipped := grid.View(Identity).ToGrid()
+	flipped.FlipH()
+	flipped.FlipV()
+	if !reflect.DeepEqual(flipped, grid.View(Rotate180).ToGrid()) {
+		t.Errorf("FlipH + FlipV: got %v", flipped)
+	}
+}
+
+func TestSubGrid(t *testing.T) {
+	grid := Grid{
+		{"a", "b", "c", "d"},
+		{"e", "f", "g", "h"},
+		{"i", "j", "k", "l"},
+	}
+
+	sub := grid.SubGrid(Coord{X: 1, Y: 1}, 2, 2)
+	if !reflect.DeepEqual(sub.ToGrid(), Grid{{"f", "g"}, {"j", "k"}}) {
+		t.Errorf("SubGrid: got %v", sub.ToGrid())
+	}
+
+	if sub.At(Coord{X: 2, Y: 0}) != "" {
+		t.Errorf("expected cells outside the window to be empty")
+	}
+
+	// views share cells with the grid
+	grid[1][1] = "F"
+	if sub.At(Coord{X: 0, Y: 0}) != "F" {
+		t.Errorf("expected the view to see changes to the grid")
+	}
+
+	// a window of a rotated view is the rotation of the matching window
+	for _, s := range AllSymmetries {
+		view := grid.View(s)
+		window := view.SubGrid(Coord{X: 1, Y: 0}, 2, 2)
+		expected := view.ToGrid().SubGrid(Coord{X: 1, Y: 0}, 2, 2).ToGrid()
+
+		if !reflect.DeepEqual(window.ToGrid(), expected) {
+			t.Errorf("%v: expected %v, got %v", s, expected, window.ToGrid())
+		}
+	}
+}
+
+func TestViewExtent(t *testing.T) {
+	grid := Grid{
+		{".", ".", ".", "."},
+		{".", "#", ".", "."},
+		{".", ".", "#", "."},
+	}
+
+	// a view's extent is the occupied box, just like the grid's, in the
+	// view's own coordinates
+	for _, s := range AllSymmetries {
+		view := grid.View(s)
+		low, high := view.Extent()
+		expectedLow, expectedHigh := view.ToGrid().Extent()
+
+		if low != expectedLow || high != expectedHigh {
+			t.Errorf("%v: expected %v-%v, got %v-%v", s, expectedLow, expectedHigh, low, high)
+		}
+	}
+
+	if low, high := grid.SubGrid(Coord{X: 1, Y: 0}, 3, 3).Extent(); low != (Coord{X: 0, Y: 1}) || high != (Coord{X: 1, Y: 2}) {
+		t.Errorf("SubGrid: expected {0 1}-{1 2}, got %v-%v", low, high)
+	}
+}